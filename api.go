@@ -6,6 +6,7 @@ import (
 	"unsafe"
 )
 
+// Marshal returns the Avro encoding of v, using the schema derived from v.
 func Marshal(v any) (p []byte, err error) {
 	schema, schemaErr := base.ParseValue(v)
 	if schemaErr != nil {
@@ -16,6 +17,7 @@ func Marshal(v any) (p []byte, err error) {
 	return
 }
 
+// Unmarshal parses the Avro encoded data using the schema derived from v and stores the result in the value pointed to by v.
 func Unmarshal(p []byte, v any) (err error) {
 	schema, schemaErr := base.ParseValue(v)
 	if schemaErr != nil {
@@ -26,10 +28,73 @@ func Unmarshal(p []byte, v any) (err error) {
 	return
 }
 
+// UnmarshalWithWriterSchema parses the Avro encoded data that was written with the writer schema,
+// resolves it against the schema derived from v and stores the result in the value pointed to by v.
+func UnmarshalWithWriterSchema(writerSchema base.Schema, p []byte, v any) (err error) {
+	schema, schemaErr := base.ParseValue(v)
+	if schemaErr != nil {
+		err = schemaErr
+		return
+	}
+	err = base.UnmarshalWithWriterSchema(schema, writerSchema, p, v)
+	return
+}
+
+// MarshalJSON returns the Avro JSON encoding of v, using the schema derived from v.
+func MarshalJSON(v any) (p []byte, err error) {
+	schema, schemaErr := base.ParseValue(v)
+	if schemaErr != nil {
+		err = schemaErr
+		return
+	}
+	p, err = base.MarshalJSON(schema, v)
+	return
+}
+
+// UnmarshalJSON parses the Avro JSON encoded data using the schema derived from v and stores the result in the value pointed to by v.
+func UnmarshalJSON(p []byte, v any) (err error) {
+	schema, schemaErr := base.ParseValue(v)
+	if schemaErr != nil {
+		err = schemaErr
+		return
+	}
+	err = base.UnmarshalJSON(schema, p, v)
+	return
+}
+
+// MarshalGeneric returns the Avro encoding of the generic value v using schema.
+//
+// Records are map[string]any, unions are nil or a map of the union type name to the value,
+// enums are strings, and logical types are time.Time, time.Duration and *big.Rat.
+func MarshalGeneric(schema base.Schema, v any) ([]byte, error) {
+	return base.MarshalGeneric(schema, v)
+}
+
+// UnmarshalGeneric parses the Avro encoded data using schema and returns it as a generic value,
+// without a Go type known at compile time. See MarshalGeneric for the generic values of the schema types.
+func UnmarshalGeneric(schema base.Schema, p []byte) (any, error) {
+	return base.UnmarshalGeneric(schema, p)
+}
+
+// Register derives the schema of v with the default config and caches it.
+// Fields of interfaces without types given to RegisterInterface, such as any, are a union of null
+// and the registered types implementing the interface, in order of registration.
 func Register(v any) {
 	base.RegisterSchemaByValue(v)
 }
 
+// RegisterInterface registers the types making up the union of fields of the interface with the default config.
+// The interface is given as a nil pointer to it, and the union is null followed by the types, in the given order:
+//
+//	avro.RegisterInterface((*Payload)(nil), &Created{}, Deleted{})
+//	avro.RegisterInterface((*any)(nil), Bar{}, "")
+//
+// Register interfaces before the types that use them, as derived schemas are cached.
+func RegisterInterface(iface any, types ...any) {
+	base.RegisterInterface(iface, types...)
+}
+
+// SchemaOf returns the canonical form of the schema derived from v.
 func SchemaOf(v any) (p []byte, err error) {
 	s, parseErr := base.ParseValue(v)
 	if parseErr != nil {
@@ -41,14 +106,41 @@ func SchemaOf(v any) (p []byte, err error) {
 	return
 }
 
+// Marshaler is implemented by types encoding themselves.
+//
+// Their derived schema is bytes, with the Go type in the go.type property, unless they implement SchemaProvider.
+// The bytes are the content of a bytes schema, or the Avro encoding of any other schema.
 type Marshaler interface {
 	MarshalAvro() ([]byte, error)
 }
 
+// Unmarshaler is implemented by types decoding themselves, see Marshaler for the bytes.
 type Unmarshaler interface {
 	UnmarshalAvro(p []byte) error
 }
 
+// SchemaProvider is implemented by types declaring their own schema.
+//
+// The types are encoded by their Marshaler, or else by the codec of the declared schema.
+type SchemaProvider = base.SchemaProvider
+
+// AvroEnum is implemented by types encoded as Avro enums, such as string types of symbol constants,
+// types implementing encoding.TextMarshaler and encoding.TextUnmarshaler, or integer types of the
+// index of their symbol.
+type AvroEnum = base.AvroEnum
+
+// AvroEnumDefault is implemented by enums having a default symbol, which is decoded instead of the symbols
+// of writer schemas unknown to the reader.
+type AvroEnumDefault = base.AvroEnumDefault
+
+// AvroNamer is implemented by types overriding the name and namespace of their derived schema,
+// such as types whose names collide.
+type AvroNamer = base.AvroNamer
+
+// GoTypeProp is the schema property recording the Go type of Marshaler types encoded as bytes.
+const GoTypeProp = base.GoTypeProp
+
+// RawMessage is a raw encoded Avro bytes value.
 type RawMessage []byte
 
 func (r *RawMessage) UnmarshalAvro(p []byte) error {
@@ -64,7 +156,6 @@ func MustMarshal(v any) (p []byte) {
 	b, err := Marshal(v)
 	if err != nil {
 		panic(fmt.Errorf("avro: marshal failed, %v", err))
-		return
 	}
 	p = b
 	return
@@ -74,7 +165,5 @@ func MustUnmarshal(p []byte, v any) {
 	err := Unmarshal(p, v)
 	if err != nil {
 		panic(fmt.Errorf("avro: unmarshal failed, %v", err))
-		return
 	}
-	return
 }
@@ -1,10 +1,18 @@
 package avro_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/aacfactory/avro"
 	"github.com/aacfactory/avro/internal/base"
+	"io"
+	"math"
 	"math/big"
+	"net/netip"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -213,3 +221,1557 @@ func TestMap(t *testing.T) {
 	}
 	t.Log(v)
 }
+
+type User struct {
+	Name  string  `avro:"name"`
+	Age   float64 `avro:"age"`
+	Email string  `avro:"email"`
+}
+
+func TestUnmarshalWithWriterSchema(t *testing.T) {
+	writer, parseErr := base.ParseWithCache(`{
+		"type": "record",
+		"name": "User",
+		"fields": [
+			{"name": "age", "type": "int"},
+			{"name": "removed", "type": {"type": "array", "items": "string"}},
+			{"name": "email", "type": ["null", "string"]},
+			{"name": "name", "type": "string"}
+		]
+	}`, "", &base.SchemaCache{})
+	if parseErr != nil {
+		t.Error(parseErr)
+		return
+	}
+	p, err := base.Marshal(writer, map[string]any{
+		"age":     18,
+		"removed": []string{"a", "b"},
+		"email":   "foo@bar.com",
+		"name":    "foo",
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	v := User{}
+	err = avro.UnmarshalWithWriterSchema(writer, p, &v)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if v.Name != "foo" || v.Age != 18 || v.Email != "foo@bar.com" {
+		t.Error("writer schema not resolved", v)
+		return
+	}
+	t.Log(v)
+
+	reader, parseErr := base.ParseWithCache(`{
+		"type": "record",
+		"name": "User",
+		"fields": [
+			{"name": "name", "type": "string"},
+			{"name": "age", "type": "double"},
+			{"name": "email", "type": "string", "default": "none"}
+		]
+	}`, "", &base.SchemaCache{})
+	if parseErr != nil {
+		t.Error(parseErr)
+		return
+	}
+	writer, parseErr = base.ParseWithCache(`{
+		"type": "record",
+		"name": "User",
+		"fields": [
+			{"name": "name", "type": "string"},
+			{"name": "age", "type": "long"}
+		]
+	}`, "", &base.SchemaCache{})
+	if parseErr != nil {
+		t.Error(parseErr)
+		return
+	}
+	p, err = base.Marshal(writer, map[string]any{"name": "bar", "age": int64(20)})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	v = User{}
+	err = base.UnmarshalWithWriterSchema(reader, writer, p, &v)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if v.Email != "none" || v.Age != 20 {
+		t.Error("default not resolved", v)
+		return
+	}
+	t.Log(v)
+}
+
+func TestUnmarshalNullIntoValue(t *testing.T) {
+	reader, parseErr := base.ParseWithCache(`{
+		"type": "record",
+		"name": "User",
+		"fields": [
+			{"name": "name", "type": "string"},
+			{"name": "age", "type": "double"},
+			{"name": "email", "type": ["null", "string"]}
+		]
+	}`, "", &base.SchemaCache{})
+	if parseErr != nil {
+		t.Error(parseErr)
+		return
+	}
+	writer, parseErr := base.ParseWithCache(`{
+		"type": "record",
+		"name": "User",
+		"fields": [
+			{"name": "name", "type": "string"},
+			{"name": "age", "type": "int"},
+			{"name": "email", "type": ["string", "null"]}
+		]
+	}`, "", &base.SchemaCache{})
+	if parseErr != nil {
+		t.Error(parseErr)
+		return
+	}
+	p, err := base.Marshal(writer, map[string]any{"name": "foo", "age": 18, "email": nil})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	// The value is reused, its email is replaced by the zero value
+	v := User{Name: "bar", Email: "hello"}
+	err = base.UnmarshalWithWriterSchema(reader, writer, p, &v)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if v.Name != "foo" || v.Age != 18 || v.Email != "" {
+		t.Error("null not decoded as the zero value", v)
+		return
+	}
+
+	// Null is not decoded into a value of a reader field that is not nullable and has no default
+	v = User{Name: "bar", Email: "hello"}
+	err = avro.UnmarshalWithWriterSchema(writer, p, &v)
+	if err == nil {
+		t.Error("null is decoded into a field that is not nullable", v)
+		return
+	}
+	t.Log(err)
+	p, err = base.Marshal(writer, map[string]any{"name": "foo", "age": 18, "email": "foo@bar.com"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = avro.UnmarshalWithWriterSchema(writer, p, &v)
+	if err != nil || v.Email != "foo@bar.com" {
+		t.Error("string of a nullable writer field is not decoded", v, err)
+		return
+	}
+}
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+)
+
+func (Level) AvroSymbols() []string {
+	return []string{"DEBUG", "INFO", "WARN"}
+}
+
+type LogLine struct {
+	Level Level `avro:"level"`
+}
+
+func TestUnmarshalIntEnumWithWriterSchema(t *testing.T) {
+	p, err := avro.Marshal(LogLine{Level: LevelWarn})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	v := LogLine{}
+	if err = avro.Unmarshal(p, &v); err != nil || v.Level != LevelWarn {
+		t.Error("int enum does not round trip", v, err)
+		return
+	}
+	writer, err := avro.ParseWithCache(`{"type":"record","name":"LogLine","fields":[
+		{"name":"level","type":{"type":"enum","name":"github.com.aacfactory.avro_test.Level","symbols":["WARN","INFO"]}}
+	]}`, "", &avro.SchemaCache{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p, err = avro.MarshalGeneric(writer, map[string]any{"level": "INFO"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	v = LogLine{}
+	err = avro.UnmarshalWithWriterSchema(writer, p, &v)
+	if err != nil || v.Level != LevelInfo {
+		t.Error("int enum is not resolved to the index of the reader symbol", v, err)
+		return
+	}
+	if _, err = avro.Marshal(LogLine{Level: 7}); err == nil {
+		t.Error("int enum of no symbol is encoded")
+		return
+	}
+	t.Log(v)
+}
+
+func TestMarshalWithSchema(t *testing.T) {
+	schema, err := avro.Parse(`{"type":"record","name":"Bar","fields":[{"name":"string","type":"string"},{"name":"next","type":["null","Bar"]}]}`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	api := avro.Config{TagKey: "avro", BlockLength: 10}.Freeze()
+	p, err := api.Marshal(schema, Bar{String: "bar", Next: &Bar{String: "next"}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	v := Bar{}
+	err = avro.UnmarshalWithSchema(schema, p, &v)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(v.String, v.Next.String)
+}
+
+type Payload interface {
+	Kind() string
+}
+
+type Created struct {
+	Id string `avro:"id"`
+}
+
+func (c *Created) Kind() string {
+	return "created"
+}
+
+type Deleted struct {
+	Id     string `avro:"id"`
+	Reason string `avro:"reason"`
+}
+
+func (d Deleted) Kind() string {
+	return "deleted"
+}
+
+type Event struct {
+	Payload Payload `avro:"payload"`
+	Extra   any     `avro:"extra"`
+}
+
+func TestInterface(t *testing.T) {
+	api := avro.Config{}.Freeze()
+	api.RegisterInterface((*Payload)(nil), &Created{}, Deleted{})
+	api.RegisterInterface((*any)(nil), Bar{}, "")
+	s, err := api.ParseValue(Event{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(s.String())
+	if _, err = avro.SchemaOf(Event{}); err == nil {
+		t.Error("interfaces registered with a config leak into the default config")
+		return
+	}
+	events := []Event{
+		{Payload: &Created{Id: "1"}, Extra: Bar{String: "bar"}},
+		{Payload: Deleted{Id: "2", Reason: "expired"}, Extra: "extra"},
+		{},
+	}
+	for _, event := range events {
+		p, encodeErr := api.Marshal(s, event)
+		if encodeErr != nil {
+			t.Error(encodeErr)
+			return
+		}
+		v := Event{}
+		decodeErr := api.Unmarshal(s, p, &v)
+		if decodeErr != nil {
+			t.Error(decodeErr)
+			return
+		}
+		if !reflect.DeepEqual(v, event) {
+			t.Error("interface round trip failed", v)
+			return
+		}
+		t.Logf("%#v", v)
+	}
+}
+
+type Note struct {
+	Text string `avro:"text"`
+}
+
+type Notice struct {
+	Level int `avro:"level"`
+}
+
+type Memo struct {
+	Body any `avro:"body"`
+}
+
+func TestRegisteredInterface(t *testing.T) {
+	avro.Register(Note{})
+	avro.Register(&Notice{})
+	s, err := avro.DefaultConfig.ParseValue(Memo{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(s.String())
+	body := s.(*avro.RecordSchema).Fields()[0].Type().(*avro.UnionSchema)
+	if len(body.Types()) != 3 {
+		t.Error("registered types are not the union of any", body)
+		return
+	}
+	memos := []Memo{{Body: Note{Text: "note"}}, {Body: &Notice{Level: 2}}, {}}
+	for _, memo := range memos {
+		p, encodeErr := avro.Marshal(memo)
+		if encodeErr != nil {
+			t.Error(encodeErr)
+			return
+		}
+		v := Memo{}
+		decodeErr := avro.Unmarshal(p, &v)
+		if decodeErr != nil {
+			t.Error(decodeErr)
+			return
+		}
+		if !reflect.DeepEqual(v, memo) {
+			t.Error("registered interface round trip failed", v)
+			return
+		}
+		t.Logf("%#v", v)
+	}
+}
+
+func TestSingleObject(t *testing.T) {
+	p, err := avro.MarshalSingleObject(Bar{String: "bar"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	fingerprint, err := avro.SingleObjectHeader(p)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Logf("%x %x", p[:2], fingerprint)
+	v := Bar{}
+	err = avro.UnmarshalSingleObject(nil, p, &v)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if v.String != "bar" || v.Next != nil {
+		t.Error("single object round trip failed", v)
+		return
+	}
+
+	writer := avro.MustParse(`{"type":"record","name":"User","fields":[{"name":"name","type":"string"},{"name":"age","type":"int"},{"name":"email","type":"string"}]}`)
+	store, err := avro.NewSchemaStore(writer)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p, err = avro.DefaultConfig.MarshalSingleObject(writer, map[string]any{"name": "foo", "age": 18, "email": "foo@bar.com"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	u := User{}
+	err = avro.UnmarshalSingleObject(store, p, &u)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if u != (User{Name: "foo", Age: 18, Email: "foo@bar.com"}) {
+		t.Error("single object of the store failed", u)
+		return
+	}
+	// Change the fingerprint that follows the two bytes of the marker
+	p[2]++
+	if err = avro.UnmarshalSingleObject(store, p, &u); err == nil {
+		t.Error("single object of an unknown fingerprint is decoded")
+		return
+	}
+	t.Log(err)
+}
+
+func TestJSON(t *testing.T) {
+	foo := Foo{
+		String:  "foo",
+		Boolean: true,
+		Int:     1,
+		Long:    2,
+		Float:   3.3,
+		Double:  4.4,
+		Time:    time.Now(),
+		Bytes:   []byte{0, 'a', 0xff},
+		Bar:     Bar{String: "bar", Next: &Bar{String: "next"}},
+		Bars:    []Bar{{String: "bar-1"}},
+		Map:     map[string]Bar{"bar2": {String: "bar-2"}},
+	}
+	p, err := avro.MarshalJSON(foo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(p))
+	r := Foo{}
+	err = avro.UnmarshalJSON(p, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.Bar.Next == nil || r.Bar.Next.String != "next" || string(r.Bytes) != string(foo.Bytes) {
+		t.Error("json round trip failed", r)
+		return
+	}
+
+	schema := avro.MustParse(`{"type":"record","name":"JSONUser","fields":[{"name":"name","type":"string"},{"name":"age","type":["null","int"],"default":null}]}`)
+	buf := bytes.NewBuffer(nil)
+	enc := avro.NewJSONEncoder(schema, buf)
+	for _, v := range []map[string]any{{"name": "foo", "age": 1}, {"name": "bar", "age": nil}} {
+		if err = enc.Encode(v); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	buf.WriteString(`{"name": "baz"}`)
+	t.Log(buf.String())
+	dec := avro.NewJSONDecoder(schema, buf)
+	want := []map[string]any{{"name": "foo", "age": 1}, {"name": "bar", "age": nil}, {"name": "baz", "age": nil}}
+	for i := 0; ; i++ {
+		v := map[string]any{}
+		err = dec.Decode(&v)
+		if err != nil {
+			if err != io.EOF {
+				t.Error(err)
+				return
+			}
+			if i != len(want) {
+				t.Error("json decoded", i, "values instead of", len(want))
+			}
+			break
+		}
+		if i >= len(want) || !reflect.DeepEqual(v, want[i]) {
+			t.Error("json decode failed", i, v)
+			return
+		}
+		t.Log(v)
+	}
+
+	schema = avro.MustParse(`{"type":"record","name":"JSONBlob","fields":[{"name":"data","type":"bytes","default":"\u00ff"}]}`)
+	v := map[string]any{}
+	if err = avro.NewJSONDecoder(schema, strings.NewReader(`{}`)).Decode(&v); err != nil || !bytes.Equal(v["data"].([]byte), []byte{0xff}) {
+		t.Error("json bytes default failed", v, err)
+		return
+	}
+	if err = avro.NewJSONDecoder(schema, strings.NewReader(`{"data":"\u0100"}`)).Decode(&v); err == nil {
+		t.Error("json bytes above U+00FF are decoded")
+		return
+	}
+	t.Log(err)
+	if _, err = avro.Parse(`{"type":"record","name":"JSONBlob","fields":[{"name":"data","type":"bytes","default":"\u0100"}]}`); err == nil {
+		t.Error("bytes default above U+00FF is parsed")
+		return
+	}
+	t.Log(err)
+}
+
+func TestGeneric(t *testing.T) {
+	schema := avro.MustParse(`{"type":"record","name":"GenericUser","fields":[
+		{"name":"name","type":"string"},
+		{"name":"id","type":["null","string","long"]},
+		{"name":"created","type":{"type":"long","logicalType":"timestamp-millis"}},
+		{"name":"balance","type":{"type":"bytes","logicalType":"decimal","precision":8,"scale":2}},
+		{"name":"tags","type":{"type":"array","items":"string"}}
+	]}`)
+	v := map[string]any{
+		"name":    "foo",
+		"id":      map[string]any{"long": int64(1)},
+		"created": time.Now(),
+		"balance": big.NewRat(1234, 100),
+		"tags":    []any{"a", "b"},
+	}
+	p, err := avro.MarshalGeneric(schema, v)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r, err := avro.UnmarshalGeneric(schema, p)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	m, ok := r.(map[string]any)
+	if !ok || m["name"] != "foo" || m["id"].(map[string]any)["long"] != int64(1) {
+		t.Error("generic round trip failed", r)
+		return
+	}
+	t.Log(m, m["balance"].(*big.Rat).FloatString(2))
+}
+
+type Tagged struct {
+	Created time.Time `avro:"created,logical=timestamp-millis"`
+	ID      [16]byte  `avro:"id,fixed=16,logical=uuid"`
+	Amount  *big.Rat  `avro:"amount,decimal=18:4"`
+	Note    string    `avro:"note,default=\"\",doc=free text, if any"`
+	Name    string    `avro:"name,alias=title"`
+	Email   string    `avro:",omitempty"`
+}
+
+func TestTagOptions(t *testing.T) {
+	schema, err := avro.DefaultConfig.ParseValue(Tagged{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	// The canonical form of SchemaOf strips docs, aliases and defaults
+	s, err := json.Marshal(schema)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(s))
+	for _, field := range []string{
+		`{"name":"note","doc":"free text, if any","type":"string","default":""}`,
+		`{"name":"name","aliases":["title"],"type":"string"}`,
+		`{"name":"Email","type":["null","string"],"default":null}`,
+	} {
+		if !strings.Contains(string(s), field) {
+			t.Error("tag options are not derived", field, string(s))
+			return
+		}
+	}
+	v := Tagged{Created: time.Now(), ID: [16]byte{1, 2, 3}, Amount: big.NewRat(1234, 100), Name: "foo"}
+	p, err := avro.Marshal(v)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Tagged{Email: "bar@foo.com"}
+	err = avro.Unmarshal(p, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.Email != "" || r.ID != v.ID || r.Amount.Cmp(v.Amount) != 0 || !r.Created.Equal(v.Created.Truncate(time.Millisecond)) {
+		t.Error("tag options round trip failed", r)
+		return
+	}
+	t.Log(r)
+}
+
+type Optional struct {
+	Name    *string         `avro:"name"`
+	Age     *int64          `avro:"age"`
+	Created *time.Time      `avro:"created"`
+	Data    *[]byte         `avro:"data"`
+	Bars    *map[string]Bar `avro:"bars"`
+	Scores  []*float64      `avro:"scores"`
+}
+
+func TestNullablePtr(t *testing.T) {
+	s, err := avro.SchemaOf(Optional{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(s))
+	name, age, now, data, score := "foo", int64(18), time.Now(), []byte("data"), 1.5
+	bars := map[string]Bar{"bar": {String: "bar"}}
+	for _, v := range []Optional{{}, {Name: &name, Age: &age, Created: &now, Data: &data, Bars: &bars, Scores: []*float64{&score, nil}}} {
+		p, err := avro.Marshal(v)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		r := Optional{}
+		err = avro.Unmarshal(p, &r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if (v.Name == nil) != (r.Name == nil) || (v.Bars == nil) != (r.Bars == nil) || len(r.Scores) != len(v.Scores) {
+			t.Error("nullable round trip failed", r)
+			return
+		}
+		t.Log(r)
+	}
+}
+
+type Point struct {
+	X int `avro:"x"`
+	Y int `avro:"y"`
+}
+
+type Keyed struct {
+	IDs    map[int64]string   `avro:"ids"`
+	Addrs  map[netip.Addr]int `avro:"addrs"`
+	Points map[Point]string   `avro:"points,entries"`
+	Counts map[string]int     `avro:"counts,entries"`
+}
+
+func TestMapKeys(t *testing.T) {
+	s, err := avro.SchemaOf(Keyed{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(s))
+	v := Keyed{
+		IDs:    map[int64]string{-1: "foo"},
+		Addrs:  map[netip.Addr]int{netip.MustParseAddr("10.0.0.1"): 1},
+		Points: map[Point]string{{X: 1, Y: 2}: "bar"},
+		Counts: map[string]int{"baz": 3},
+	}
+	p, err := avro.Marshal(v)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Keyed{}
+	err = avro.Unmarshal(p, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.IDs[-1] != "foo" || r.Addrs[netip.MustParseAddr("10.0.0.1")] != 1 || r.Points[Point{X: 1, Y: 2}] != "bar" || r.Counts["baz"] != 3 {
+		t.Error("map keys round trip failed", r)
+		return
+	}
+	t.Log(r)
+}
+
+type Scores map[Point]int
+
+func TestMapEntries(t *testing.T) {
+	if _, err := avro.SchemaOf(Scores{}); err == nil {
+		t.Error("maps of record keys must be entries")
+		return
+	}
+	api := avro.Config{MapEntries: true}.Freeze()
+	schema, err := api.ParseValue(Scores{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(schema.String())
+	if schema.Type() != avro.Array || !strings.Contains(schema.String(), `Scores_entry"`) {
+		t.Error("map is not an array of entries", schema)
+		return
+	}
+	keyed, err := api.ParseValue(Keyed{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if keyed.(*avro.RecordSchema).Fields()[0].Type().Type() != avro.Array {
+		t.Error("map field is not an array of entries", keyed)
+		return
+	}
+	p, err := api.Marshal(schema, Scores{{X: 1, Y: 2}: 3})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Scores{}
+	err = api.Unmarshal(schema, p, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r[Point{X: 1, Y: 2}] != 3 {
+		t.Error("map entries round trip failed", r)
+		return
+	}
+	t.Log(r)
+}
+
+type Celsius float64
+
+func (c Celsius) AvroSchema() string {
+	return `{"type":"record","name":"Temperature","fields":[{"name":"celsius","type":"double"}]}`
+}
+
+func (c Celsius) MarshalAvro() ([]byte, error) {
+	return avro.MarshalWithSchema(avro.MustParse(`"double"`), float64(c))
+}
+
+func (c *Celsius) UnmarshalAvro(p []byte) error {
+	return avro.UnmarshalWithSchema(avro.MustParse(`"double"`), p, (*float64)(c))
+}
+
+type Reading struct {
+	Raw         avro.RawMessage `avro:"raw"`
+	Temperature Celsius         `avro:"temperature"`
+}
+
+func TestMarshalerSchema(t *testing.T) {
+	s, err := avro.SchemaOf(Reading{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(s))
+	schema, err := avro.ParseWithCache(string(s), "", &avro.SchemaCache{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if schema.(*avro.RecordSchema).Fields()[0].Type().Type() != avro.Bytes {
+		t.Error("raw message is not bytes", schema)
+		return
+	}
+	p, err := avro.Marshal(Reading{Raw: avro.RawMessage("foo"), Temperature: 21.5})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := map[string]any{}
+	err = avro.UnmarshalWithSchema(schema, p, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(r)
+	reading := Reading{}
+	err = avro.Unmarshal(p, &reading)
+	if err != nil || reading.Temperature != 21.5 {
+		t.Error("marshaler round trip failed", reading, err)
+		return
+	}
+}
+
+type Envelope struct {
+	Body Any `avro:"body"`
+}
+
+func TestMarshalerBytes(t *testing.T) {
+	plain := []byte{0x06, 'f', 'o', 'o'}
+	p, err := avro.Marshal(Envelope{Body: Any{p: []byte("foo")}})
+	if err != nil || !bytes.Equal(p, plain) {
+		t.Error("plain bytes are not encoded", p, err)
+		return
+	}
+	v := Envelope{}
+	err = avro.Unmarshal(p, &v)
+	if err != nil || string(v.Body.p) != "foo" {
+		t.Error("plain bytes are not decoded", string(v.Body.p), err)
+		return
+	}
+	// Former versions wrote the bytes of marshalers in a second bytes
+	legacy := []byte{0x08, 0x06, 'f', 'o', 'o'}
+	api := avro.Config{LegacyRawFraming: true}.Freeze()
+	schema, err := api.ParseValue(Envelope{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p, err = api.Marshal(schema, Envelope{Body: Any{p: []byte("foo")}})
+	if err != nil || !bytes.Equal(p, legacy) {
+		t.Error("legacy bytes are not encoded", p, err)
+		return
+	}
+	v = Envelope{}
+	err = api.Unmarshal(schema, legacy, &v)
+	if err != nil || string(v.Body.p) != "foo" {
+		t.Error("legacy bytes are not decoded", string(v.Body.p), err)
+		return
+	}
+}
+
+type Cents struct {
+	value int64
+}
+
+type Invoice struct {
+	Total Cents  `avro:"total"`
+	Tax   *Cents `avro:"tax"`
+}
+
+func TestRegisterCodec(t *testing.T) {
+	avro.RegisterCodec[Cents](avro.MustParse(`"long"`), func(w *avro.Writer, v Cents) {
+		w.WriteLong(v.value)
+	}, func(r *avro.Reader) Cents {
+		return Cents{value: r.ReadLong()}
+	})
+	schema, err := avro.SchemaOf(Invoice{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(schema))
+	p, err := avro.Marshal(Invoice{Total: Cents{value: 1250}, Tax: &Cents{value: 250}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Invoice{}
+	err = avro.Unmarshal(p, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.Total.value != 1250 || r.Tax == nil || r.Tax.value != 250 {
+		t.Error("codec round trip failed", r)
+		return
+	}
+	t.Log(r.Total, *r.Tax)
+}
+
+type UUID [16]byte
+
+type Account struct {
+	ID      UUID     `avro:"id,uuid"`
+	Owner   [16]byte `avro:"owner,uuid"`
+	Session string   `avro:"session,uuid"`
+	Hash    UUID     `avro:"hash"`
+}
+
+func TestUUID(t *testing.T) {
+	schema, err := avro.SchemaOf(Account{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(schema))
+	if strings.Count(string(schema), `"logicalType":"uuid"`) != 3 {
+		t.Error("only tagged fields are uuids", string(schema))
+		return
+	}
+	account := Account{
+		ID:      UUID{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8},
+		Hash:    UUID{0x01, 0x02},
+		Owner:   [16]byte{0x6b, 0xa7, 0xb8, 0x11},
+		Session: "6ba7b812-9dad-11d1-80b4-00c04fd430c8",
+	}
+	p, err := avro.Marshal(account)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Account{}
+	err = avro.Unmarshal(p, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r != account {
+		t.Error("uuid round trip failed", r)
+		return
+	}
+	_, err = avro.Marshal(Account{Session: "6ba7b812"})
+	if err == nil {
+		t.Error("invalid uuid is encoded")
+		return
+	}
+	t.Log(err)
+}
+
+func TestUint(t *testing.T) {
+	schema, err := avro.SchemaOf(uint64(0))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(schema))
+	for _, s := range []string{`"long"`, `{"type":"fixed","name":"go.uint64","size":8}`, `{"type":"bytes","logicalType":"decimal","precision":20}`} {
+		schema := avro.MustParse(s)
+		v := uint64(math.MaxInt64)
+		if schema.Type() != avro.Long {
+			v = math.MaxUint64
+		}
+		p, err := avro.MarshalWithSchema(schema, v)
+		if err != nil {
+			t.Error(s, err)
+			return
+		}
+		r := uint64(0)
+		err = avro.UnmarshalWithSchema(schema, p, &r)
+		if err != nil || r != v {
+			t.Error(s, "uint round trip failed", r, err)
+			return
+		}
+	}
+	_, err = avro.MarshalWithSchema(avro.MustParse(`"long"`), uint64(math.MaxUint64))
+	if err == nil {
+		t.Error("uint overflow is encoded")
+		return
+	}
+	t.Log(err)
+}
+
+type Schedule struct {
+	At      time.Time     `avro:"at"`
+	Nanos   time.Time     `avro:"nanos,logical=timestamp-nanos"`
+	Local   time.Time     `avro:"local,logical=local-timestamp-millis"`
+	Elapsed time.Duration `avro:"elapsed"`
+	Timeout time.Duration `avro:"timeout,logical=time-millis"`
+	Delay   time.Duration `avro:"delay,logical=time-micros"`
+}
+
+type Meter struct {
+	Count uint64    `avro:"count"`
+	At    time.Time `avro:"at"`
+}
+
+func TestUintOfFormerVersions(t *testing.T) {
+	writer, err := avro.ParseWithCache(`{"type":"record","name":"github.com.aacfactory.avro_test.Meter","fields":[
+		{"name":"count","type":{"type":"fixed","name":"uint","size":8}},
+		{"name":"at","type":{"type":"long","logicalType":"timestamp-micros"}}
+	]}`, "", &avro.SchemaCache{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p, err := avro.MarshalWithSchema(writer, Meter{Count: 7})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Meter{}
+	err = avro.UnmarshalWithWriterSchema(writer, p, &r)
+	if err != nil || r.Count != 7 {
+		t.Error("uint of former versions is not resolved", r, err)
+		return
+	}
+	t.Log(r)
+}
+
+func TestUintConcurrency(t *testing.T) {
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		go func(count uint64) {
+			for j := 0; j < 100; j++ {
+				p, err := avro.Marshal(Meter{Count: count})
+				if err != nil {
+					errs <- err
+					return
+				}
+				r := Meter{}
+				if err = avro.Unmarshal(p, &r); err != nil {
+					errs <- err
+					return
+				}
+				if r.Count != count {
+					errs <- fmt.Errorf("count %d is decoded as %d", count, r.Count)
+					return
+				}
+			}
+			errs <- nil
+		}(uint64(i) << 40)
+	}
+	for i := 0; i < 8; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+			return
+		}
+	}
+}
+
+func TestSetDefaultConfig(t *testing.T) {
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		go func(name string) {
+			for j := 0; j < 100; j++ {
+				p, err := avro.Marshal(Bar{String: name})
+				if err != nil {
+					errs <- err
+					return
+				}
+				r := Bar{}
+				if err = avro.Unmarshal(p, &r); err != nil {
+					errs <- err
+					return
+				}
+				if r.String != name {
+					errs <- fmt.Errorf("%s is decoded as %s", name, r.String)
+					return
+				}
+			}
+			errs <- nil
+		}(fmt.Sprint("bar-", i))
+	}
+	for i := 0; i < 100; i++ {
+		avro.SetDefaultConfig(avro.Config{})
+	}
+	for i := 0; i < 4; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+			return
+		}
+	}
+}
+
+func TestConfigDerivation(t *testing.T) {
+	api := avro.Config{UintStrategy: avro.UintLong, TimePrecision: avro.PrecisionMillis}.Freeze()
+	schema, err := api.ParseValue(Meter{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if s := schema.String(); !strings.Contains(s, `{"name":"count","type":"long"}`) || !strings.Contains(s, `"timestamp-millis"`) {
+		t.Error("config is not applied", s)
+		return
+	}
+	t.Log(schema.String())
+	def, err := avro.SchemaOf(Meter{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if s := string(def); !strings.Contains(s, `"name":"go.uint"`) || !strings.Contains(s, `"timestamp-micros"`) {
+		t.Error("config leaks into the default config", s)
+		return
+	}
+	now := time.Now().Truncate(time.Millisecond)
+	p, err := api.Marshal(schema, Meter{Count: 7, At: now})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Meter{}
+	err = api.Unmarshal(schema, p, &r)
+	if err != nil || r.Count != 7 || !r.At.Equal(now) {
+		t.Error("config round trip failed", r, err)
+		return
+	}
+	t.Log(string(def))
+}
+
+func TestTime(t *testing.T) {
+	schema, err := avro.SchemaOf(Schedule{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(schema))
+	now := time.Now()
+	event := Schedule{
+		At:      now,
+		Nanos:   now,
+		Local:   now,
+		Elapsed: 90 * time.Minute,
+		Timeout: 3 * time.Second,
+		Delay:   1500 * time.Microsecond,
+	}
+	p, err := avro.Marshal(event)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Schedule{}
+	err = avro.Unmarshal(p, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !r.At.Equal(now.Truncate(time.Microsecond)) || !r.Nanos.Equal(now) ||
+		!r.Local.Equal(now.Truncate(time.Millisecond)) || r.Elapsed != event.Elapsed || r.Timeout != event.Timeout || r.Delay != event.Delay {
+		t.Error("time round trip failed", r)
+		return
+	}
+	event.Nanos = time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err = avro.Marshal(event); err == nil {
+		t.Error("timestamp-nanos out of range is encoded")
+		return
+	}
+	t.Log(r)
+}
+
+func TestDuration(t *testing.T) {
+	p, err := avro.Marshal(time.Duration(1500))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.Equal(p, []byte{0xb8, 0x17}) {
+		t.Error("duration is not encoded as a long of nanoseconds", p)
+		return
+	}
+	s, err := avro.DefaultConfig.ParseValue(Schedule{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	schema, err := json.Marshal(s)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.Contains(string(schema), `{"name":"elapsed","type":{"type":"long","go.duration":"nanos"}}`) ||
+		!strings.Contains(string(schema), `"logicalType":"time-micros"`) {
+		t.Error("duration schemas are not derived", string(schema))
+		return
+	}
+	t.Log(p)
+}
+
+type Address struct {
+	City string `avro:"city"`
+}
+
+type Profile struct {
+	Name     string         `avro:"name"`
+	Nickname string         `avro:"nickname,default=\"anonymous\""`
+	Tags     []string       `avro:"tags,default=[\"new\"]"`
+	Limits   map[string]int `avro:"limits,default={\"daily\":10}"`
+	Home     Address        `avro:"home,default={\"city\":\"Paris\"}"`
+}
+
+func TestDefaults(t *testing.T) {
+	writer, err := avro.ParseWithCache(`{"type":"record","name":"github.com.aacfactory.avro_test.Profile","fields":[
+		{"name":"name","type":"string"},
+		{"name":"nickname","type":["null","string"],"default":null}
+	]}`, "", &avro.SchemaCache{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p, err := avro.MarshalGeneric(writer, map[string]any{"name": "foo", "nickname": nil})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Profile{}
+	err = avro.UnmarshalWithWriterSchema(writer, p, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.Nickname != "anonymous" || len(r.Tags) != 1 || r.Limits["daily"] != 10 || r.Home.City != "Paris" {
+		t.Error("defaults are not applied", r)
+		return
+	}
+	t.Log(r)
+	reader, err := avro.ParseWithCache(`{"type":"record","name":"Profile","fields":[
+		{"name":"name","type":"string"},
+		{"name":"nickname","type":"string","default":"anonymous"},
+		{"name":"level","type":{"type":"enum","name":"Level","symbols":["LOW","HIGH"]},"default":"LOW"},
+		{"name":"home","type":{"type":"record","name":"Address","fields":[{"name":"city","type":"string"}]},"default":{"city":"Paris"}}
+	]}`, "", &avro.SchemaCache{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	g := map[string]any{}
+	err = avro.DefaultConfig.UnmarshalWithWriterSchema(reader, writer, p, &g)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if g["nickname"] != "anonymous" || g["level"] != "LOW" || g["home"].(map[string]any)["city"] != "Paris" {
+		t.Error("generic defaults are not applied", g)
+		return
+	}
+	t.Log(g)
+}
+
+type Severity string
+
+const (
+	SeverityLow  Severity = "LOW"
+	SeverityHigh Severity = "HIGH"
+)
+
+func (Severity) AvroSymbols() []string {
+	return []string{string(SeverityLow), string(SeverityHigh)}
+}
+
+func (Severity) AvroEnumDefault() string {
+	return string(SeverityLow)
+}
+
+type Alert struct {
+	Severity Severity `avro:"severity"`
+}
+
+func TestEnum(t *testing.T) {
+	schema, err := avro.SchemaOf(Alert{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(schema))
+	p, err := avro.Marshal(Alert{Severity: SeverityHigh})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Alert{}
+	err = avro.Unmarshal(p, &r)
+	if err != nil || r.Severity != SeverityHigh {
+		t.Error("enum round trip failed", r, err)
+		return
+	}
+	_, err = avro.Marshal(Alert{Severity: "CRITICAL"})
+	if err == nil {
+		t.Error("unknown symbol is encoded")
+		return
+	}
+	writer, err := avro.ParseWithCache(`{"type":"enum","name":"Severity","symbols":["LOW","HIGH","CRITICAL"]}`, "", &avro.SchemaCache{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p, err = avro.MarshalWithSchema(writer, "CRITICAL")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	severity := SeverityHigh
+	err = avro.UnmarshalWithWriterSchema(writer, p, &severity)
+	if err != nil || severity != SeverityLow {
+		t.Error("enum default is not decoded", severity, err)
+		return
+	}
+	err = avro.Unmarshal(p, &severity)
+	if err == nil {
+		t.Error("unknown enum index is decoded", severity)
+		return
+	}
+	t.Log(err)
+}
+
+type Page[T any] struct {
+	Items []T `avro:"items"`
+	Next  *T  `avro:"next"`
+}
+
+type LegacyAddress struct {
+	City string `avro:"city"`
+}
+
+func (LegacyAddress) AvroName() (string, string) {
+	return "Address", "legacy"
+}
+
+type Directory struct {
+	Addresses Page[Address]       `avro:"addresses"`
+	Legacy    Page[LegacyAddress] `avro:"legacy"`
+	Owner     struct {
+		Name string `avro:"name"`
+	} `avro:"owner"`
+}
+
+func TestSchemaNames(t *testing.T) {
+	schema, err := avro.SchemaOf(Directory{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(schema))
+	if !strings.Contains(string(schema), `"name":"github.com.aacfactory.avro_test.Page_github_com_aacfactory_avro_test_Address"`) ||
+		!strings.Contains(string(schema), `"name":"legacy.Address"`) {
+		t.Error("unexpected schema names", string(schema))
+		return
+	}
+	if _, err = avro.ParseWithCache(string(schema), "", &avro.SchemaCache{}); err != nil {
+		t.Error(err)
+		return
+	}
+	d := Directory{
+		Addresses: Page[Address]{Items: []Address{{City: "Paris"}}},
+		Legacy:    Page[LegacyAddress]{Next: &LegacyAddress{City: "Rome"}},
+	}
+	d.Owner.Name = "foo"
+	p, err := avro.Marshal(d)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Directory{}
+	err = avro.Unmarshal(p, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.Addresses.Items[0].City != "Paris" || r.Legacy.Next.City != "Rome" || r.Owner.Name != "foo" {
+		t.Error("round trip failed", r)
+		return
+	}
+}
+
+type Digest [4]byte
+
+type Packet struct {
+	Digest Digest     `avro:"digest"`
+	Addr   [16]byte   `avro:"addr"`
+	Coords [3]float64 `avro:"coords"`
+}
+
+func TestArrays(t *testing.T) {
+	schema, err := avro.SchemaOf(Packet{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(schema))
+	packet := Packet{
+		Digest: Digest{1, 2, 3, 4},
+		Addr:   [16]byte{15: 1},
+		Coords: [3]float64{1.5, 2.5, 3.5},
+	}
+	p, err := avro.Marshal(packet)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Packet{}
+	err = avro.Unmarshal(p, &r)
+	if err != nil || r != packet {
+		t.Error("array round trip failed", r, err)
+		return
+	}
+	p, err = avro.MarshalWithSchema(avro.MustParse(`{"type":"array","items":"double"}`), []float64{1, 2})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	coords := [3]float64{}
+	err = avro.Unmarshal(p, &coords)
+	if err == nil {
+		t.Error("array of another length is decoded")
+		return
+	}
+	t.Log(err)
+}
+
+type Payment struct {
+	Amount   big.Rat  `avro:"amount,decimal=18:2"`
+	Fee      *big.Rat `avro:"fee,decimal=9:2,fixed=8"`
+	Cents    int64    `avro:"cents,decimal=18:2"`
+	Rate     string   `avro:"rate,decimal=10:3"`
+	Discount int64    `avro:"discount,decimal=6:2,fixed=4"`
+}
+
+type Refund struct {
+	Fee *big.Rat `avro:"fee,decimal=9:2,omitempty"`
+}
+
+func TestDecimal(t *testing.T) {
+	schema, err := avro.SchemaOf(Payment{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(string(schema))
+	payment := Payment{
+		Amount:   *big.NewRat(-12345, 100),
+		Fee:      big.NewRat(1, 4),
+		Cents:    1999,
+		Rate:     "0.125",
+		Discount: -250,
+	}
+	p, err := avro.Marshal(payment)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r := Payment{}
+	err = avro.Unmarshal(p, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.Amount.Cmp(&payment.Amount) != 0 || r.Fee.Cmp(payment.Fee) != 0 || r.Cents != 1999 || r.Rate != "0.125" || r.Discount != -250 {
+		t.Error("decimal round trip failed", r)
+		return
+	}
+	p, err = avro.Marshal(Payment{Fee: new(big.Rat)})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r = Payment{}
+	err = avro.Unmarshal(p, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.Amount.Sign() != 0 || r.Fee.Sign() != 0 || r.Rate != "0.000" {
+		t.Error("zero decimals round trip failed", r)
+		return
+	}
+	if _, err = avro.Marshal(Payment{}); err == nil {
+		t.Error("nil decimal is encoded")
+		return
+	}
+	t.Log(err)
+	p, err = avro.Marshal(Refund{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	refund := Refund{Fee: big.NewRat(1, 4)}
+	err = avro.Unmarshal(p, &refund)
+	if err != nil || refund.Fee != nil {
+		t.Error("nullable nil decimal round trip failed", refund, err)
+		return
+	}
+	payment.Fee = big.NewRat(10_000_000, 1)
+	_, err = avro.Marshal(payment)
+	if err == nil {
+		t.Error("decimal of a greater precision is encoded")
+		return
+	}
+	t.Log(err)
+	payment.Fee = big.NewRat(1, 3)
+	_, err = avro.Marshal(payment)
+	if err == nil {
+		t.Error("decimal of a greater scale is encoded")
+		return
+	}
+	t.Log(err)
+}
+
+func TestCompatibility(t *testing.T) {
+	versions := []avro.Schema{
+		avro.MustParse(`{"type":"record","name":"Order","fields":[
+			{"name":"id","type":"long"},
+			{"name":"items","type":{"type":"array","items":{"type":"record","name":"Item","fields":[{"name":"price","type":"long"}]}}}
+		]}`),
+		avro.MustParse(`{"type":"record","name":"Order","fields":[
+			{"name":"id","type":"long"},
+			{"name":"items","type":{"type":"array","items":{"type":"record","name":"Item","fields":[{"name":"price","type":"long"}]}}},
+			{"name":"note","type":"string","default":""}
+		]}`),
+	}
+	schema := avro.MustParse(`{"type":"record","name":"Order","fields":[
+		{"name":"id","type":"long"},
+		{"name":"items","type":{"type":"array","items":{"type":"record","name":"Item","fields":[{"name":"price","type":"string"}]}}},
+		{"name":"note","type":"string","default":""},
+		{"name":"status","type":"string"}
+	]}`)
+	compatibility := avro.NewSchemaCompatibility()
+	report, err := compatibility.Check(avro.CompatibilityFullTransitive, schema, versions)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(report.Err())
+	paths := map[string]bool{}
+	for _, i := range report.Incompatibilities {
+		paths[i.String()] = true
+	}
+	if len(report.Incompatibilities) != 6 || !paths["Order.items[].price: type changed long→string"] || !paths["Order.status: field added without default"] {
+		t.Error("unexpected report", report.Incompatibilities)
+		return
+	}
+	report, err = compatibility.Check(avro.CompatibilityBackward, versions[1], versions[:1])
+	if err != nil || !report.Compatible() {
+		t.Error("added field with default is not backward compatible", report, err)
+		return
+	}
+}
+
+type Mail struct {
+	To      string `avro:"to"`
+	Subject string `avro:"subject"`
+}
+
+type Receipt struct {
+	ID string `avro:"id"`
+}
+
+type MailError struct {
+	Reason string `avro:"reason"`
+}
+
+func (e *MailError) Error() string {
+	return e.Reason
+}
+
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) (Receipt, error)
+	Track(ctx context.Context, mail Mail) (*Receipt, error)
+	Ping(ctx context.Context) error
+	Notify(mail *Mail)
+}
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type PingError struct {
+	Reason string `avro:"reason"`
+}
+
+func (e *PingError) Error() string {
+	return e.Reason
+}
+
+func TestProtocolOf(t *testing.T) {
+	protocol, err := avro.ProtocolOf((*Mailer)(nil), avro.WithErrors(&MailError{}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(protocol.Declaration())
+	if strings.Contains(protocol.String(), `"one-way"`) || strings.Contains(protocol.String(), `"response":"null"`) {
+		t.Error("canonical form of the hash is not the former one", protocol.String())
+		return
+	}
+	parsed, err := avro.ParseProtocol(protocol.Declaration())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	send := parsed.Message("send")
+	if send == nil || send.OneWay() || len(send.Request().Fields()) != 2 || len(send.Errors().Types()) != 2 {
+		t.Error("send message is not derived", send)
+		return
+	}
+	if notify := parsed.Message("notify"); notify == nil || !notify.OneWay() {
+		t.Error("notify message is not one-way", notify)
+		return
+	}
+	if notify := protocol.Message("notify"); notify.Errors() == nil || len(notify.Errors().Types()) != 1 {
+		t.Error("one-way message has not the string error of parsed messages", notify)
+		return
+	}
+	if track := protocol.Message("track"); track.Response().Type() != avro.Union || track.Request().Name() != "track" {
+		t.Error("pointer response is not nullable", track)
+		return
+	}
+	if parsed.Message("ping") == nil || parsed.Hash() != protocol.Hash() {
+		t.Error("protocol does not round trip")
+		return
+	}
+	pinger, err := avro.ProtocolOf((*Pinger)(nil), avro.WithErrors(&PingError{}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(pinger.String())
+	if strings.Contains(pinger.String(), "MailError") || strings.Contains(protocol.String(), "PingError") {
+		t.Error("errors of a protocol leak into another")
+		return
+	}
+	again, err := avro.ProtocolOf((*Mailer)(nil), avro.WithErrors(&MailError{}))
+	if err != nil || again.Hash() != protocol.Hash() {
+		t.Error("protocol is not stable", err)
+		return
+	}
+	_, err = avro.ProtocolOf(Mail{})
+	if err == nil {
+		t.Error("protocol of a struct is derived")
+		return
+	}
+	t.Log(err)
+}
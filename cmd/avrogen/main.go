@@ -0,0 +1,93 @@
+// Command avrogen generates Go types from Avro schema (.avsc) and protocol (.avpr) files.
+//
+// Usage:
+//
+//	avrogen -pkg models -o models.go schemas/user.avsc schemas/service.avpr
+//
+// Schema files are parsed in the order they are given, so a schema can use the
+// named types of the files before it.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/aacfactory/avro"
+	"github.com/aacfactory/avro/gen"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("avrogen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	pkg := flags.String("pkg", "", "The package name of the generated file.")
+	out := flags.String("o", "", "The output file path, defaults to stdout.")
+	enumsAsInts := flags.Bool("enum-int", false, "Generate enums as int types implementing encoding.TextMarshaler.")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "Usage: avrogen [options] schemas")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *pkg == "" || flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	g := gen.NewGenerator(gen.Config{
+		PackageName: *pkg,
+		EnumsAsInts: *enumsAsInts,
+	})
+
+	cache := &avro.SchemaCache{}
+	for _, path := range flags.Args() {
+		if err := parseFile(g, cache, path); err != nil {
+			_, _ = fmt.Fprintf(stderr, "avrogen: %s: %v\n", path, err)
+			return 1
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := g.Write(buf); err != nil {
+		_, _ = fmt.Fprintf(stderr, "avrogen: %v\n", err)
+		return 1
+	}
+
+	if *out == "" {
+		_, _ = stdout.Write(buf.Bytes())
+		return 0
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		_, _ = fmt.Fprintf(stderr, "avrogen: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func parseFile(g *gen.Generator, cache *avro.SchemaCache, path string) error {
+	if filepath.Ext(path) == ".avpr" {
+		protocol, err := avro.ParseProtocolFile(path)
+		if err != nil {
+			return err
+		}
+		return g.ParseProtocol(protocol)
+	}
+
+	p, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	schema, err := avro.ParseBytesWithCache(p, "", cache)
+	if err != nil {
+		return err
+	}
+	return g.Parse(schema)
+}
@@ -0,0 +1,87 @@
+package avro
+
+import (
+	"io"
+
+	"github.com/aacfactory/avro/internal/base"
+)
+
+type (
+	// Reader is an Avro specific io.Reader.
+	Reader = base.Reader
+	// ReaderFunc is a function used to customize the Reader.
+	ReaderFunc = base.ReaderFunc
+	// Writer is an Avro specific io.Writer.
+	Writer = base.Writer
+	// WriterFunc is a function used to customize the Writer.
+	WriterFunc = base.WriterFunc
+	// Decoder reads and decodes Avro values from an input stream.
+	Decoder = base.Decoder
+	// Encoder writes Avro values to an output stream.
+	Encoder = base.Encoder
+	// JSONDecoder reads and decodes Avro JSON encoded values from an input stream.
+	JSONDecoder = base.JSONDecoder
+	// JSONEncoder writes Avro JSON encoded values to an output stream.
+	JSONEncoder = base.JSONEncoder
+)
+
+// NewReader creates a new Reader.
+func NewReader(r io.Reader, bufSize int, opts ...ReaderFunc) *Reader {
+	return base.NewReader(r, bufSize, opts...)
+}
+
+// WithReaderConfig specifies the configuration to use with a reader.
+func WithReaderConfig(cfg API) ReaderFunc {
+	return base.WithReaderConfig(cfg)
+}
+
+// NewWriter creates a new Writer.
+func NewWriter(out io.Writer, bufSize int, opts ...WriterFunc) *Writer {
+	return base.NewWriter(out, bufSize, opts...)
+}
+
+// WithWriterConfig specifies the configuration to use with a writer.
+func WithWriterConfig(cfg API) WriterFunc {
+	return base.WithWriterConfig(cfg)
+}
+
+// NewDecoder returns a new decoder that reads from reader r using schema s.
+func NewDecoder(s string, r io.Reader) (*Decoder, error) {
+	return base.NewDecoder(s, r)
+}
+
+// NewDecoderForSchema returns a new decoder that reads from r using schema.
+func NewDecoderForSchema(schema Schema, r io.Reader) *Decoder {
+	return base.NewDecoderForSchema(schema, r)
+}
+
+// NewEncoder returns a new encoder that writes to w using schema s.
+func NewEncoder(s string, w io.Writer) (*Encoder, error) {
+	return base.NewEncoder(s, w)
+}
+
+// NewEncoderForSchema returns a new encoder that writes to w using schema.
+func NewEncoderForSchema(schema Schema, w io.Writer) *Encoder {
+	return base.NewEncoderForSchema(schema, w)
+}
+
+// NewJSONDecoder returns a new JSON decoder that reads from r using schema.
+func NewJSONDecoder(schema Schema, r io.Reader) *JSONDecoder {
+	return base.NewJSONDecoder(schema, r)
+}
+
+// NewJSONEncoder returns a new JSON encoder that writes to w using schema.
+func NewJSONEncoder(schema Schema, w io.Writer) *JSONEncoder {
+	return base.NewJSONEncoder(schema, w)
+}
+
+// MarshalWithSchema returns the Avro encoding of v using schema.
+func MarshalWithSchema(schema Schema, v any) ([]byte, error) {
+	return base.Marshal(schema, v)
+}
+
+// UnmarshalWithSchema parses the Avro encoded data using schema and stores the result in the value pointed to by v.
+// If v is nil or not a pointer, UnmarshalWithSchema returns an error.
+func UnmarshalWithSchema(schema Schema, data []byte, v any) error {
+	return base.Unmarshal(schema, data, v)
+}
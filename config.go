@@ -0,0 +1,76 @@
+package avro
+
+import (
+	"github.com/aacfactory/avro/internal/base"
+)
+
+type (
+	// Config customises how the codec should behave.
+	Config = base.Config
+	// API represents a frozen Config.
+	API = base.API
+	// ValDecoder represents an internal value decoder.
+	ValDecoder = base.ValDecoder
+	// ValEncoder represents an internal value encoder.
+	ValEncoder = base.ValEncoder
+	// TypeResolver resolves types by name.
+	TypeResolver = base.TypeResolver
+	// UintStrategy determines the Avro type uint and uint64 values are mapped to.
+	UintStrategy = base.UintStrategy
+	// TimePrecision is the precision of timestamps in schemas derived from time.Time.
+	TimePrecision = base.TimePrecision
+)
+
+const (
+	// PrecisionMicros derives timestamp-micros.
+	PrecisionMicros = base.PrecisionMicros
+	// PrecisionMillis derives timestamp-millis.
+	PrecisionMillis = base.PrecisionMillis
+	// PrecisionNanos derives timestamp-nanos.
+	PrecisionNanos = base.PrecisionNanos
+)
+
+const (
+	// UintFixed maps unsigned integers to the big-endian fixed(8) go.uint, with the go.uint64 property.
+	UintFixed = base.UintFixed
+	// UintLong maps unsigned integers to long, failing to encode values over math.MaxInt64.
+	UintLong = base.UintLong
+	// UintDecimal maps unsigned integers to bytes of decimal(20,0).
+	UintDecimal = base.UintDecimal
+)
+
+// UintProp is the schema property marking fixed(8) schemas of unsigned integers.
+const UintProp = base.UintProp
+
+// DurationProp is the schema property marking long schemas of time.Duration nanoseconds.
+const DurationProp = base.DurationProp
+
+// DefaultConfig is the default API. It uses the config set by SetDefaultConfig, and is never reassigned.
+var DefaultConfig = base.DefaultConfig
+
+// SetDefaultConfig replaces the config of the DefaultConfig with the frozen c, keeping the registered types, codecs and
+// interfaces. Schemas derived by the former config are kept only if c derives the same schemas.
+//
+// It is safe to call concurrently with encoding and decoding, which use either the former or the new config.
+func SetDefaultConfig(c Config) {
+	base.SetDefaultConfig(c)
+}
+
+// NewTypeResolver creates a new type resolver with all primitive types
+// registered.
+func NewTypeResolver() *TypeResolver {
+	return base.NewTypeResolver()
+}
+
+// RegisterType registers names to their types for resolution with the default config.
+func RegisterType(name string, obj any) {
+	base.Register(name, obj)
+}
+
+// RegisterCodec binds the encode and decode functions of T to schema with the default config,
+// which also becomes the derived schema of T.
+//
+// Register codecs before T is first used, as codecs and derived schemas are cached.
+func RegisterCodec[T any](schema Schema, encode func(w *Writer, v T), decode func(r *Reader) T) {
+	base.RegisterCodecFunc[T](schema, encode, decode)
+}
@@ -0,0 +1,12 @@
+// Package avro implements encoding and decoding of Avro as defined by the Avro specification.
+//
+// Marshal and Unmarshal derive the schema from the Go value. Custom schemas can be
+// parsed with Parse or built with the schema constructors, and used with MarshalWithSchema,
+// UnmarshalWithSchema, NewEncoder, NewDecoder or a frozen Config.
+//
+// The identifiers exported by this package are the supported API. They follow semantic
+// versioning: within a major version they are neither removed nor changed incompatibly.
+// Packages under internal are implementation details and may change at any time.
+//
+// See the Avro specification for an understanding of Avro: http://avro.apache.org/docs/current/
+package avro
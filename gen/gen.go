@@ -0,0 +1,557 @@
+// Package gen generates Go types from Avro schemas.
+//
+// The generated types follow the conventions of the schemas derived by avro.Marshal, records
+// are structs with `avro` tags, nullable types are pointers and other unions are interfaces
+// registered with avro.RegisterInterface, whose derived unions begin with null.
+// Named types implement avro.AvroNamer, so that their derived schemas keep their names.
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aacfactory/avro"
+)
+
+// Config configures the generator.
+type Config struct {
+	// PackageName is the package name of the generated file.
+	PackageName string
+
+	// EnumsAsInts generates enums as int types implementing encoding.TextMarshaler
+	// instead of string types.
+	EnumsAsInts bool
+}
+
+// Generator generates Go types from Avro schemas.
+type Generator struct {
+	cfg     Config
+	imports map[string]struct{}
+	defined map[string]struct{}
+	names   map[string]string   // Go names of full names
+	taken   map[string]struct{} // Go names
+	types   []string
+	inits   []string
+}
+
+// NewGenerator returns a new generator.
+func NewGenerator(cfg Config) *Generator {
+	if cfg.PackageName == "" {
+		cfg.PackageName = "avro"
+	}
+	return &Generator{
+		cfg:     cfg,
+		imports: map[string]struct{}{},
+		defined: map[string]struct{}{},
+		names:   map[string]string{},
+		taken:   map[string]struct{}{},
+	}
+}
+
+// Parse adds the types of the schema to the generator.
+func (g *Generator) Parse(schema avro.Schema) error {
+	_, err := g.resolveType(schema, "Union")
+	return err
+}
+
+// ParseProtocol adds the types of the protocol to the generator.
+func (g *Generator) ParseProtocol(protocol *avro.Protocol) error {
+	for _, typ := range protocol.Types() {
+		if err := g.Parse(typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write writes the formatted Go file of the parsed types to w.
+func (g *Generator) Write(w io.Writer) error {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("// Code generated by avrogen. DO NOT EDIT.\n\n")
+	buf.WriteString("package " + g.cfg.PackageName + "\n\n")
+
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for imp := range g.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+		buf.WriteString("import (\n")
+		for _, imp := range imports {
+			buf.WriteString(strconv.Quote(imp) + "\n")
+		}
+		buf.WriteString(")\n\n")
+	}
+
+	for _, typ := range g.types {
+		buf.WriteString(typ)
+		buf.WriteString("\n")
+	}
+
+	if len(g.inits) > 0 {
+		buf.WriteString("func init() {\n")
+		for _, init := range g.inits {
+			buf.WriteString(init)
+		}
+		buf.WriteString("}\n")
+	}
+
+	p, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("gen: format failed, %v", err)
+	}
+	_, err = w.Write(p)
+	return err
+}
+
+// resolveType returns the Go type of the schema, naming the interfaces of unions after name.
+func (g *Generator) resolveType(schema avro.Schema, name string) (string, error) {
+	switch schema.Type() {
+	case avro.Ref:
+		return g.resolveType(schema.(*avro.RefSchema).Schema(), name)
+	case avro.Record, avro.Error:
+		return g.generateRecord(schema.(*avro.RecordSchema))
+	case avro.Enum:
+		return g.generateEnum(schema.(*avro.EnumSchema))
+	case avro.Fixed:
+		return g.generateFixed(schema.(*avro.FixedSchema))
+	case avro.Array:
+		items, err := g.resolveType(schema.(*avro.ArraySchema).Items(), name)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + items, nil
+	case avro.Map:
+		values, err := g.resolveType(schema.(*avro.MapSchema).Values(), name)
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + values, nil
+	case avro.Union:
+		return g.resolveUnion(schema.(*avro.UnionSchema), name)
+	case avro.Null:
+		return "any", nil
+	case avro.Boolean:
+		return "bool", nil
+	case avro.Float:
+		return "float32", nil
+	case avro.Double:
+		return "float64", nil
+	case avro.String:
+		return "string", nil
+	case avro.Int:
+		return "int", nil
+	case avro.Long:
+		if logicalType(schema) == avro.TimestampMicros {
+			// Times derive timestamp-micros, other time types need the logical tag of fields
+			g.imports["time"] = struct{}{}
+			return "time.Time", nil
+		}
+		return "int64", nil
+	case avro.Bytes:
+		if logicalType(schema) == avro.Decimal {
+			g.imports["math/big"] = struct{}{}
+			return "*big.Rat", nil
+		}
+		return "[]byte", nil
+	}
+	return "", fmt.Errorf("gen: schema type %s is unsupported", schema.Type())
+}
+
+// resolveFieldType returns the Go type of the field, which are time types for time logical types,
+// as the logical tag of the field keeps their logical type.
+func (g *Generator) resolveFieldType(record string, field *avro.Field) (string, error) {
+	schema := field.Type()
+	union, isUnion := schema.(*avro.UnionSchema)
+	if isUnion && union.Nullable() {
+		_, typeIdx := union.Indices()
+		schema = union.Types()[typeIdx]
+	}
+	typ := timeType(schema)
+	if typ == "" {
+		return g.resolveType(field.Type(), record+fieldName(field.Name()))
+	}
+	g.imports["time"] = struct{}{}
+	if isUnion {
+		return "*" + typ, nil
+	}
+	return typ, nil
+}
+
+// timeType returns the Go time type of time logical types, or an empty string.
+func timeType(schema avro.Schema) string {
+	switch logicalType(schema) {
+	case avro.Date, avro.TimestampMillis, avro.TimestampMicros, avro.TimestampNanos,
+		avro.LocalTimestampMillis, avro.LocalTimestampMicros, avro.LocalTimestampNanos:
+		return "time.Time"
+	case avro.TimeMillis, avro.TimeMicros:
+		return "time.Duration"
+	}
+	if prim, ok := schema.(*avro.PrimitiveSchema); ok && prim.Type() == avro.Long && prim.Prop(avro.DurationProp) != nil {
+		return "time.Duration"
+	}
+	return ""
+}
+
+func (g *Generator) resolveUnion(union *avro.UnionSchema, name string) (string, error) {
+	if union.Nullable() {
+		_, typeIdx := union.Indices()
+		typ, err := g.resolveType(union.Types()[typeIdx], name)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasPrefix(typ, "*") {
+			return typ, nil
+		}
+		return "*" + typ, nil
+	}
+
+	// Other unions are interfaces of the types of the union, registered in an init function
+	var values []string
+	for _, typ := range union.Types() {
+		if ref, ok := typ.(*avro.RefSchema); ok {
+			typ = ref.Schema()
+		}
+		if typ.Type() == avro.Null {
+			continue
+		}
+		goType, err := g.resolveType(typ, name)
+		if err != nil {
+			return "", err
+		}
+		if typ.Type() == avro.Record || typ.Type() == avro.Error {
+			values = append(values, goType+"{}")
+			continue
+		}
+		values = append(values, "*new("+goType+")")
+	}
+	ifaceName := g.goName("", name, "")
+	buf := bytes.NewBuffer(nil)
+	writeDoc(buf, ifaceName, "", "is the Avro union of "+unionNames(union)+".")
+	buf.WriteString("type " + ifaceName + " interface{}\n")
+	g.types = append(g.types, buf.String())
+
+	g.imports["github.com/aacfactory/avro"] = struct{}{}
+	g.inits = append(g.inits, "avro.RegisterInterface((*"+ifaceName+")(nil), "+strings.Join(values, ", ")+")\n")
+	return ifaceName, nil
+}
+
+// unionNames returns the names of the types of the union, such as null, string and example.User.
+func unionNames(union *avro.UnionSchema) string {
+	names := make([]string, 0, len(union.Types()))
+	for _, typ := range union.Types() {
+		if ref, ok := typ.(*avro.RefSchema); ok {
+			typ = ref.Schema()
+		}
+		if named, ok := typ.(avro.NamedSchema); ok {
+			names = append(names, named.FullName())
+			continue
+		}
+		names = append(names, string(typ.Type()))
+	}
+	return strings.Join(names, ", ")
+}
+
+func (g *Generator) generateRecord(schema *avro.RecordSchema) (string, error) {
+	name := g.goName(schema.FullName(), schema.Name(), schema.Namespace())
+	if g.define(schema.FullName()) {
+		return name, nil
+	}
+
+	// Reserve the position of the record before the types of its fields
+	pos := len(g.types)
+	g.types = append(g.types, "")
+
+	buf := bytes.NewBuffer(nil)
+	writeDoc(buf, name, schema.Doc(), "is the Avro record "+schema.FullName()+".")
+	buf.WriteString("type " + name + " struct {\n")
+	for _, field := range schema.Fields() {
+		typ, err := g.resolveFieldType(name, field)
+		if err != nil {
+			return "", fmt.Errorf("gen: %s.%s: %w", schema.FullName(), field.Name(), err)
+		}
+		if doc := field.Doc(); doc != "" {
+			writeComment(buf, doc)
+		}
+		tag, err := fieldTag(field)
+		if err != nil {
+			return "", fmt.Errorf("gen: %s.%s: %w", schema.FullName(), field.Name(), err)
+		}
+		fmt.Fprintf(buf, "%s %s `avro:%q`\n", fieldName(field.Name()), typ, tag)
+	}
+	buf.WriteString("}\n\n")
+	writeAvroName(buf, name, "record", schema.Name(), schema.Namespace())
+
+	g.types[pos] = buf.String()
+	return name, nil
+}
+
+func (g *Generator) generateEnum(schema *avro.EnumSchema) (string, error) {
+	name := g.goName(schema.FullName(), schema.Name(), schema.Namespace())
+	if g.define(schema.FullName()) {
+		return name, nil
+	}
+
+	buf := bytes.NewBuffer(nil)
+	writeDoc(buf, name, schema.Doc(), "is the Avro enum "+schema.FullName()+".")
+	if !g.cfg.EnumsAsInts {
+		buf.WriteString("type " + name + " string\n\n")
+		buf.WriteString("// " + name + " symbols.\n")
+		buf.WriteString("const (\n")
+		for _, sym := range schema.Symbols() {
+			fmt.Fprintf(buf, "%s%s %s = %q\n", name, typeName(sym), name, sym)
+		}
+		buf.WriteString(")\n\n")
+		writeEnumSymbols(buf, name, schema)
+		writeAvroName(buf, name, "enum", schema.Name(), schema.Namespace())
+		g.types = append(g.types, buf.String())
+		return name, nil
+	}
+
+	g.imports["fmt"] = struct{}{}
+	buf.WriteString("type " + name + " int\n\n")
+	buf.WriteString("// " + name + " symbols.\n")
+	buf.WriteString("const (\n")
+	for i, sym := range schema.Symbols() {
+		if i == 0 {
+			fmt.Fprintf(buf, "%s%s %s = iota\n", name, typeName(sym), name)
+			continue
+		}
+		fmt.Fprintf(buf, "%s%s\n", name, typeName(sym))
+	}
+	buf.WriteString(")\n\n")
+
+	writeEnumSymbols(buf, name, schema)
+
+	fmt.Fprintf(buf, `// String returns the symbol of the enum.
+func (e %[1]s) String() string {
+	if e < 0 || int(e) >= len(%[2]sSymbols) {
+		return ""
+	}
+	return %[2]sSymbols[e]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (e %[1]s) MarshalText() ([]byte, error) {
+	if e < 0 || int(e) >= len(%[2]sSymbols) {
+		return nil, fmt.Errorf("invalid %[1]s %%d", int(e))
+	}
+	return []byte(%[2]sSymbols[e]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (e *%[1]s) UnmarshalText(b []byte) error {
+	for i, sym := range %[2]sSymbols {
+		if sym == string(b) {
+			*e = %[1]s(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown %[1]s symbol %%s", b)
+}
+
+`, name, lowerFirst(name))
+	writeAvroName(buf, name, "enum", schema.Name(), schema.Namespace())
+
+	g.types = append(g.types, buf.String())
+	return name, nil
+}
+
+// writeEnumSymbols writes the symbols of the enum and the methods implementing avro.AvroEnum.
+func writeEnumSymbols(buf *bytes.Buffer, name string, schema *avro.EnumSchema) {
+	buf.WriteString("var " + lowerFirst(name) + "Symbols = []string{")
+	for i, sym := range schema.Symbols() {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(strconv.Quote(sym))
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(buf, `// AvroSymbols returns the symbols of the enum.
+func (%[1]s) AvroSymbols() []string {
+	return %[2]sSymbols
+}
+
+`, name, lowerFirst(name))
+	if def := schema.Default(); def != "" {
+		fmt.Fprintf(buf, `// AvroEnumDefault returns the default symbol of the enum.
+func (%[1]s) AvroEnumDefault() string {
+	return %[2]q
+}
+
+`, name, def)
+	}
+}
+
+func (g *Generator) generateFixed(schema *avro.FixedSchema) (string, error) {
+	if schema.Size() == 8 && schema.Prop(avro.UintProp) == true {
+		return "uint64", nil
+	}
+	switch logicalType(schema) {
+	case avro.Decimal:
+		g.imports["math/big"] = struct{}{}
+		return "*big.Rat", nil
+	case avro.Duration:
+		g.imports["github.com/aacfactory/avro"] = struct{}{}
+		return "avro.LogicalDuration", nil
+	}
+
+	name := g.goName(schema.FullName(), schema.Name(), schema.Namespace())
+	if g.define(schema.FullName()) {
+		return name, nil
+	}
+
+	buf := bytes.NewBuffer(nil)
+	writeDoc(buf, name, "", "is the Avro fixed "+schema.FullName()+".")
+	fmt.Fprintf(buf, "type %s [%d]byte\n\n", name, schema.Size())
+	writeAvroName(buf, name, "fixed", schema.Name(), schema.Namespace())
+
+	g.types = append(g.types, buf.String())
+	return name, nil
+}
+
+// fieldTag returns the avro tag of the field, with the logical type of time fields,
+// as the derived schemas of time types may be of another precision, the uuid and decimal options,
+// and the default of the field.
+func fieldTag(field *avro.Field) (string, error) {
+	tag := field.Name()
+	if field.HasDefault() && field.Default() != nil {
+		def, err := json.Marshal(field.Default())
+		if err != nil {
+			return "", err
+		}
+		tag += ",default=" + string(def)
+	}
+	schema := field.Type()
+	if union, ok := schema.(*avro.UnionSchema); ok && union.Nullable() {
+		for _, typ := range union.Types() {
+			if typ.Type() != avro.Null {
+				schema = typ
+			}
+		}
+	}
+	switch lt := logicalType(schema); lt {
+	case avro.Date, avro.TimeMillis, avro.TimeMicros,
+		avro.TimestampMillis, avro.TimestampMicros, avro.TimestampNanos,
+		avro.LocalTimestampMillis, avro.LocalTimestampMicros, avro.LocalTimestampNanos:
+		return tag + ",logical=" + string(lt), nil
+	case avro.UUID:
+		if schema.Type() == avro.String {
+			return tag + ",uuid", nil
+		}
+	case avro.Decimal:
+		dec := schema.(avro.LogicalTypeSchema).Logical().(*avro.DecimalLogicalSchema)
+		tag += ",decimal=" + strconv.Itoa(dec.Precision()) + ":" + strconv.Itoa(dec.Scale())
+		if fixed, ok := schema.(*avro.FixedSchema); ok {
+			tag += ",fixed=" + strconv.Itoa(fixed.Size())
+		}
+		return tag, nil
+	}
+	return tag, nil
+}
+
+// writeAvroName writes the method implementing avro.AvroNamer, which keeps the name of the schema.
+func writeAvroName(buf *bytes.Buffer, name, kind, avroName, namespace string) {
+	fmt.Fprintf(buf, `// AvroName returns the name and namespace of the Avro %s.
+func (%s) AvroName() (name, namespace string) {
+	return %q, %q
+}
+`, kind, name, avroName, namespace)
+}
+
+// goName returns the Go name of the named schema, which is its name unless another schema takes it,
+// in which case the name is qualified by the last parts of its namespace, such as OrderUser for shop.order.User.
+func (g *Generator) goName(fullName, name, namespace string) string {
+	if goName, ok := g.names[fullName]; ok && fullName != "" {
+		return goName
+	}
+	goName := typeName(name)
+	parts := strings.Split(namespace, ".")
+	for i := len(parts) - 1; g.isTaken(goName); i-- {
+		if i >= 0 && parts[i] != "" {
+			goName = typeName(parts[i]) + goName
+			continue
+		}
+		goName += "_"
+	}
+	g.taken[goName] = struct{}{}
+	if fullName != "" {
+		g.names[fullName] = goName
+	}
+	return goName
+}
+
+func (g *Generator) isTaken(goName string) bool {
+	_, ok := g.taken[goName]
+	return ok
+}
+
+// define marks the named type as defined, returning true if it already was.
+func (g *Generator) define(fullName string) bool {
+	if _, ok := g.defined[fullName]; ok {
+		return true
+	}
+	g.defined[fullName] = struct{}{}
+	return false
+}
+
+func logicalType(schema avro.Schema) avro.LogicalType {
+	ls, ok := schema.(avro.LogicalTypeSchema)
+	if !ok || ls.Logical() == nil {
+		return ""
+	}
+	return ls.Logical().Type()
+}
+
+func writeDoc(buf *bytes.Buffer, name, doc, def string) {
+	writeComment(buf, name+" "+def)
+	if doc != "" {
+		buf.WriteString("//\n")
+		writeComment(buf, doc)
+	}
+}
+
+func writeComment(buf *bytes.Buffer, doc string) {
+	for _, line := range strings.Split(strings.TrimSpace(doc), "\n") {
+		buf.WriteString("// " + strings.TrimSpace(line) + "\n")
+	}
+}
+
+// typeName returns the exported Go name of an Avro name, which only differs
+// in its first letter so that the unqualified names of derived schemas match.
+func typeName(name string) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// fieldName returns the exported camel case Go name of an Avro field name.
+func fieldName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_'
+	})
+	for i, part := range parts {
+		parts[i] = typeName(part)
+	}
+	if len(parts) == 0 {
+		return "Field"
+	}
+	return strings.Join(parts, "")
+}
+
+func lowerFirst(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
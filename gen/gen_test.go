@@ -0,0 +1,143 @@
+package gen_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aacfactory/avro"
+	"github.com/aacfactory/avro/gen"
+)
+
+const userSchema = `{
+	"type": "record",
+	"name": "User",
+	"namespace": "example",
+	"doc": "User of the example.",
+	"fields": [
+		{"name": "user_id", "type": "long"},
+		{"name": "name", "type": "string", "default": "anonymous"},
+		{"name": "email", "type": ["null", "string"], "default": null},
+		{"name": "suit", "type": {"type": "enum", "name": "Suit", "symbols": ["SPADES", "HEARTS"]}},
+		{"name": "hash", "type": {"type": "fixed", "name": "MD5", "size": 16}},
+		{"name": "created", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "ttl", "type": {"type": "long", "go.duration": "nanos"}},
+		{"name": "friends", "type": {"type": "array", "items": "User"}}
+	]
+}`
+
+func TestGenerator(t *testing.T) {
+	schema, err := avro.ParseWithCache(userSchema, "", &avro.SchemaCache{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	g := gen.NewGenerator(gen.Config{PackageName: "models"})
+	if err = g.Parse(schema); err != nil {
+		t.Error(err)
+		return
+	}
+	buf := bytes.NewBuffer(nil)
+	if err = g.Write(buf); err != nil {
+		t.Error(err)
+		return
+	}
+	out := buf.String()
+	if !strings.Contains(out, "type User struct") || !strings.Contains(out, "type Suit string") ||
+		!strings.Contains(out, `default=\"anonymous\"`) || !strings.Contains(out, "time.Duration") {
+		t.Error("unexpected output", out)
+		return
+	}
+	t.Log(out)
+}
+
+const orderSchema = `{
+	"type": "record",
+	"name": "Order",
+	"namespace": "shop.order",
+	"fields": [
+		{"name": "id", "type": {"type": "string", "logicalType": "uuid"}},
+		{"name": "buyer", "type": {"type": "record", "name": "User", "fields": [{"name": "name", "type": "string"}]}},
+		{"name": "seller", "type": {"type": "record", "name": "User", "namespace": "shop.seller", "fields": [{"name": "rating", "type": "int"}]}},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["OPEN", "CLOSED"]}},
+		{"name": "hash", "type": {"type": "fixed", "name": "MD5", "size": 16}},
+		{"name": "total", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+		{"name": "timeout", "type": {"type": "int", "logicalType": "time-millis"}},
+		{"name": "created", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "note", "type": ["null", "string"], "default": null},
+		{"name": "payload", "type": ["null", "string", "long", "shop.seller.User"], "default": null},
+		{"name": "tags", "type": {"type": "map", "values": "string"}},
+		{"name": "lines", "type": {"type": "array", "items": "Order"}}
+	]
+}`
+
+func TestGeneratorRoundTrip(t *testing.T) {
+	schema, err := avro.ParseWithCache(orderSchema, "", &avro.SchemaCache{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	g := gen.NewGenerator(gen.Config{PackageName: "main"})
+	if err = g.Parse(schema); err != nil {
+		t.Error(err)
+		return
+	}
+	buf := bytes.NewBuffer(nil)
+	if err = g.Write(buf); err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(buf.String())
+
+	// The generated types are compiled in a package of the module, printing their derived schema
+	if err = os.MkdirAll("testdata", 0755); err != nil {
+		t.Error(err)
+		return
+	}
+	dir, err := os.MkdirTemp("testdata", "roundtrip")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.Remove("testdata")
+	defer os.RemoveAll(dir)
+	main := `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aacfactory/avro"
+)
+
+func main() {
+	s, err := avro.SchemaOf(Order{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Print(string(s))
+}
+`
+	if err = os.WriteFile(filepath.Join(dir, "types.go"), buf.Bytes(), 0644); err != nil {
+		t.Error(err)
+		return
+	}
+	if err = os.WriteFile(filepath.Join(dir, "main.go"), []byte(main), 0644); err != nil {
+		t.Error(err)
+		return
+	}
+	out, err := exec.Command("go", "run", "./"+dir).CombinedOutput()
+	if err != nil {
+		t.Error(err, string(out))
+		return
+	}
+	if string(out) != schema.String() {
+		t.Error("derived schema differs", string(out), schema.String())
+		return
+	}
+	t.Log(string(out))
+}
@@ -11,9 +11,10 @@ import (
 )
 
 var (
-	timeType = reflect.TypeOf(time.Time{})
-	ratType  = reflect.TypeOf(big.Rat{})
-	durType  = reflect.TypeOf(LogicalDuration{})
+	timeType         = reflect.TypeOf(time.Time{})
+	ratType          = reflect.TypeOf(big.Rat{})
+	durType          = reflect.TypeOf(LogicalDuration{})
+	timeDurationType = reflect.TypeOf(time.Duration(0))
 )
 
 type null struct{}
@@ -82,12 +83,16 @@ func (c *frozenConfig) DecoderOf(schema Schema, typ reflect2.Type) ValDecoder {
 }
 
 func decoderOfType(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValDecoder {
+	if dec := createDecoderOfRegistered(cfg, schema, typ); dec != nil {
+		return dec
+	}
+
 	if dec := createDecoderOfMarshaler(cfg, schema, typ); dec != nil {
 		return dec
 	}
 
-	if schema.Type() == Raw {
-		return createDecoderOfRaw(cfg, schema, typ)
+	if dec := createDecoderOfRaw(cfg, schema, typ); dec != nil {
+		return dec
 	}
 
 	// Handle eface case when it isnt a union
@@ -167,12 +172,16 @@ func (e *onePtrEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
 }
 
 func encoderOfType(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
+	if enc := createEncoderOfRegistered(cfg, schema, typ); enc != nil {
+		return enc
+	}
+
 	if enc := createEncoderOfMarshaler(cfg, schema, typ); enc != nil {
 		return enc
 	}
 
-	if schema.Type() == Raw {
-		return createEncoderOfRaw(cfg, schema, typ)
+	if enc := createEncoderOfRaw(cfg, schema, typ); enc != nil {
+		return enc
 	}
 
 	if typ.Kind() == reflect.Interface {
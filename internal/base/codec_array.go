@@ -14,6 +14,12 @@ func createDecoderOfArray(cfg *frozenConfig, schema Schema, typ reflect2.Type) V
 	if typ.Kind() == reflect.Slice {
 		return decoderOfArray(cfg, schema, typ)
 	}
+	if typ.Kind() == reflect.Array {
+		return decoderOfGoArray(cfg, schema, typ)
+	}
+	if typ.Kind() == reflect.Map && isMapEntriesSchema(schema) {
+		return decoderOfMapEntries(cfg, schema, typ)
+	}
 
 	return &errorDecoder{err: fmt.Errorf("avro: %s is unsupported for Avro %s", typ.String(), schema.Type())}
 }
@@ -22,6 +28,12 @@ func createEncoderOfArray(cfg *frozenConfig, schema Schema, typ reflect2.Type) V
 	if typ.Kind() == reflect.Slice {
 		return encoderOfArray(cfg, schema, typ)
 	}
+	if typ.Kind() == reflect.Array {
+		return encoderOfGoArray(cfg, schema, typ)
+	}
+	if typ.Kind() == reflect.Map && isMapEntriesSchema(schema) {
+		return encoderOfMapEntries(cfg, schema, typ)
+	}
 
 	return &errorEncoder{err: fmt.Errorf("avro: %s is unsupported for Avro %s", typ.String(), schema.Type())}
 }
@@ -113,3 +125,100 @@ func (e *arrayEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
 		w.Error = fmt.Errorf("%v: %w", e.typ, w.Error)
 	}
 }
+
+func decoderOfGoArray(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValDecoder {
+	arr := schema.(*ArraySchema)
+	arrayType := typ.(*reflect2.UnsafeArrayType)
+	decoder := decoderOfType(cfg, arr.Items(), arrayType.Elem())
+
+	return &goArrayDecoder{typ: arrayType, decoder: decoder}
+}
+
+// goArrayDecoder decodes Avro arrays into Go arrays, which must have as many items as the Go array.
+type goArrayDecoder struct {
+	typ     *reflect2.UnsafeArrayType
+	decoder ValDecoder
+}
+
+func (d *goArrayDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
+	var size int
+	length := d.typ.Len()
+
+	for {
+		l, _ := r.ReadBlockHeader()
+		if l == 0 {
+			break
+		}
+
+		start := size
+		size += int(l)
+		if size > length {
+			r.ReportError("decode array", fmt.Sprintf("%s expects %d items, got more", d.typ.String(), length))
+			return
+		}
+
+		for i := start; i < size; i++ {
+			elemPtr := d.typ.UnsafeGetIndex(ptr, i)
+			d.decoder.Decode(elemPtr, r)
+			if r.Error != nil && !errors.Is(r.Error, io.EOF) {
+				r.Error = fmt.Errorf("%s: %w", d.typ.String(), r.Error)
+				return
+			}
+		}
+	}
+
+	if size != length && r.Error == nil {
+		r.ReportError("decode array", fmt.Sprintf("%s expects %d items, got %d", d.typ.String(), length, size))
+		return
+	}
+
+	if r.Error != nil && !errors.Is(r.Error, io.EOF) {
+		r.Error = fmt.Errorf("%v: %w", d.typ, r.Error)
+	}
+}
+
+func encoderOfGoArray(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
+	arr := schema.(*ArraySchema)
+	arrayType := typ.(*reflect2.UnsafeArrayType)
+	encoder := encoderOfType(cfg, arr.Items(), arrayType.Elem())
+
+	return &goArrayEncoder{
+		blockLength: cfg.getBlockLength(),
+		typ:         arrayType,
+		encoder:     encoder,
+	}
+}
+
+type goArrayEncoder struct {
+	blockLength int
+	typ         *reflect2.UnsafeArrayType
+	encoder     ValEncoder
+}
+
+func (e *goArrayEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
+	blockLength := e.blockLength
+	length := e.typ.Len()
+
+	for i := 0; i < length; i += blockLength {
+		w.WriteBlockCB(func(w *Writer) int64 {
+			count := int64(0)
+			for j := i; j < i+blockLength && j < length; j++ {
+				elemPtr := e.typ.UnsafeGetIndex(ptr, j)
+				e.encoder.Encode(elemPtr, w)
+				if w.Error != nil && !errors.Is(w.Error, io.EOF) {
+					w.Error = fmt.Errorf("%s: %w", e.typ.String(), w.Error)
+					return count
+				}
+				count++
+			}
+
+			return count
+		})
+	}
+
+	w.WriteBlockHeader(0, 0)
+
+	if w.Error != nil && !errors.Is(w.Error, io.EOF) {
+		w.Error = fmt.Errorf("%v: %w", e.typ, w.Error)
+	}
+}
@@ -0,0 +1,163 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"unsafe"
+
+	"github.com/modern-go/reflect2"
+)
+
+type decimalCodec interface {
+	ValDecoder
+	ValEncoder
+}
+
+// createCodecOfDecimal returns the codec of scaled int64 and string decimals of bytes, or of a fixed of the size,
+// or nil if the type is neither.
+func createCodecOfDecimal(dec *DecimalLogicalSchema, size int, typ reflect2.Type) decimalCodec {
+	switch typ.Kind() {
+	case reflect.Int64:
+		return &decimalInt64Codec{prec: dec.Precision(), scale: dec.Scale(), size: size}
+	case reflect.String:
+		return &decimalStringCodec{prec: dec.Precision(), scale: dec.Scale(), size: size}
+	}
+	return nil
+}
+
+// unscaledOf returns the unscaled value of r, failing if r has more digits than the precision and scale.
+func unscaledOf(r *big.Rat, prec, scale int) (*big.Int, error) {
+	i := (&big.Int{}).Mul(r.Num(), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+	i, m := i.DivMod(i, r.Denom(), new(big.Int))
+	if m.Sign() != 0 {
+		return nil, fmt.Errorf("avro: %s has more digits than the decimal scale %d", r.RatString(), scale)
+	}
+	if new(big.Int).Abs(i).Cmp(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(prec)), nil)) >= 0 {
+		return nil, fmt.Errorf("avro: %s overflows the decimal precision %d", r.RatString(), prec)
+	}
+	return i, nil
+}
+
+// decimalBytesOf returns the big-endian two's-complement of i, sign extended to the size of fixed decimals.
+func decimalBytesOf(i *big.Int, size int) ([]byte, error) {
+	var b []byte
+	switch i.Sign() {
+	case 0:
+		b = []byte{0}
+	case 1:
+		b = i.Bytes()
+		if b[0]&0x80 > 0 {
+			b = append([]byte{0}, b...)
+		}
+	case -1:
+		length := uint(i.BitLen()/8+1) * 8
+		b = new(big.Int).Add(i, (&big.Int{}).Lsh(one, length)).Bytes()
+	}
+	if size == 0 {
+		return b, nil
+	}
+	if len(b) > size {
+		return nil, fmt.Errorf("avro: %s overflows the decimal fixed size %d", i.String(), size)
+	}
+	padded := make([]byte, size)
+	if i.Sign() < 0 {
+		for j := range padded {
+			padded[j] = 0xff
+		}
+	}
+	copy(padded[size-len(b):], b)
+	return padded, nil
+}
+
+// unscaledFromBytes returns the value of the big-endian two's-complement b.
+func unscaledFromBytes(b []byte) *big.Int {
+	i := (&big.Int{}).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 > 0 {
+		i.Sub(i, new(big.Int).Lsh(one, uint(len(b))*8))
+	}
+	return i
+}
+
+func readDecimalBytes(r *Reader, size int) []byte {
+	if size == 0 {
+		return r.ReadBytes()
+	}
+	b := make([]byte, size)
+	r.Read(b)
+	return b
+}
+
+// errNilDecimal is the error of nil *big.Rat decimals, which are encoded as null by nullable unions only,
+// such as the ones of the omitempty tag option.
+var errNilDecimal = errors.New("avro: nil *big.Rat of a decimal that is not nullable")
+
+// writeDecimal writes r as a decimal, and nil as 0.
+func writeDecimal(w *Writer, r *big.Rat, prec, scale, size int) {
+	if r == nil {
+		r = new(big.Rat)
+	}
+	i, err := unscaledOf(r, prec, scale)
+	if err != nil {
+		w.Error = err
+		return
+	}
+	b, err := decimalBytesOf(i, size)
+	if err != nil {
+		w.Error = err
+		return
+	}
+	if size == 0 {
+		w.WriteBytes(b)
+		return
+	}
+	_, _ = w.Write(b)
+}
+
+// decimalInt64Codec encodes int64 values as the unscaled value of decimals, 1234 is 12.34 of scale 2.
+type decimalInt64Codec struct {
+	prec  int
+	scale int
+	size  int
+}
+
+func (c *decimalInt64Codec) Decode(ptr unsafe.Pointer, r *Reader) {
+	i := unscaledFromBytes(readDecimalBytes(r, c.size))
+	if !i.IsInt64() {
+		r.ReportError("decimalInt64Codec", fmt.Sprintf("%s overflows int64", i.String()))
+		return
+	}
+	*((*int64)(ptr)) = i.Int64()
+}
+
+func (c *decimalInt64Codec) Encode(ptr unsafe.Pointer, w *Writer) {
+	writeDecimal(w, new(big.Rat).SetFrac(big.NewInt(*((*int64)(ptr))), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(c.scale)), nil)), c.prec, c.scale, c.size)
+}
+
+// decimalStringCodec encodes strings of decimal numbers, such as "12.34", and the empty string as 0.
+type decimalStringCodec struct {
+	prec  int
+	scale int
+	size  int
+}
+
+func (c *decimalStringCodec) Decode(ptr unsafe.Pointer, r *Reader) {
+	i := unscaledFromBytes(readDecimalBytes(r, c.size))
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(c.scale)), nil)
+	*((*string)(ptr)) = new(big.Rat).SetFrac(i, denom).FloatString(c.scale)
+}
+
+func (c *decimalStringCodec) Encode(ptr unsafe.Pointer, w *Writer) {
+	s := *((*string)(ptr))
+	if s == "" {
+		writeDecimal(w, nil, c.prec, c.scale, c.size)
+		return
+	}
+	v, ok := new(big.Rat).SetString(s)
+	if !ok {
+		w.Error = fmt.Errorf("avro: %q is not a decimal", s)
+		return
+	}
+	writeDecimal(w, v, c.prec, c.scale, c.size)
+}
@@ -0,0 +1,172 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/modern-go/reflect2"
+)
+
+func decoderOfDefault(cfg *frozenConfig, field *Field, typ reflect2.Type) ValDecoder {
+	w := cfg.borrowWriter()
+	writeDefault(w, field.Type(), field.Default())
+	if err := w.Error; err != nil {
+		cfg.returnWriter(w)
+		return &errorDecoder{err: fmt.Errorf("avro: invalid default for field %s: %w", field.Name(), err)}
+	}
+	data := make([]byte, w.Buffered())
+	copy(data, w.Buffer())
+	cfg.returnWriter(w)
+
+	return &defaultDecoder{
+		cfg:     cfg,
+		data:    data,
+		decoder: decoderOfType(cfg, field.Type(), typ),
+	}
+}
+
+// defaultDecoder decodes the Avro encoding of a field default instead of
+// reading from the reader.
+type defaultDecoder struct {
+	cfg     *frozenConfig
+	data    []byte
+	decoder ValDecoder
+}
+
+func (d *defaultDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
+	reader := d.cfg.borrowReader(d.data)
+	d.decoder.Decode(ptr, reader)
+	if err := reader.Error; err != nil && !errors.Is(err, io.EOF) {
+		r.ReportError("decode default", err.Error())
+	}
+	d.cfg.returnReader(reader)
+}
+
+// writeDefault writes the Avro encoding of a default value, as it is
+// validated by NewField, to the writer.
+func writeDefault(w *Writer, schema Schema, def any) {
+	var ok bool
+	switch schema.Type() {
+	case Ref:
+		writeDefault(w, schema.(*RefSchema).Schema(), def)
+		return
+	case Null:
+		ok = def == nil || def == nullDefault
+	case Boolean:
+		var v bool
+		if v, ok = def.(bool); ok {
+			w.WriteBool(v)
+		}
+	case Int:
+		var v int
+		if v, ok = def.(int); ok {
+			w.WriteInt(int32(v))
+		}
+	case Long:
+		var v int64
+		if v, ok = def.(int64); ok {
+			w.WriteLong(v)
+		}
+	case Float:
+		var v float32
+		if v, ok = def.(float32); ok {
+			w.WriteFloat(v)
+		}
+	case Double:
+		var v float64
+		if v, ok = def.(float64); ok {
+			w.WriteDouble(v)
+		}
+	case String:
+		var v string
+		if v, ok = def.(string); ok {
+			w.WriteString(v)
+		}
+	case Bytes:
+		var v string
+		if v, ok = def.(string); ok {
+			var b []byte
+			if b, ok = bytesOfDefault(v); ok {
+				w.WriteBytes(b)
+			}
+		}
+	case Fixed:
+		var v string
+		if v, ok = def.(string); ok {
+			var b []byte
+			if b, ok = bytesOfDefault(v); ok {
+				if ok = len(b) == schema.(*FixedSchema).Size(); ok {
+					_, _ = w.Write(b)
+				}
+			}
+		}
+	case Enum:
+		var v string
+		if v, ok = def.(string); ok {
+			ok = false
+			for i, sym := range schema.(*EnumSchema).Symbols() {
+				if sym == v {
+					w.WriteInt(int32(i))
+					ok = true
+					break
+				}
+			}
+		}
+	case Array:
+		var v []any
+		if v, ok = def.([]any); ok {
+			items := schema.(*ArraySchema).Items()
+			if len(v) > 0 {
+				w.WriteBlockHeader(int64(len(v)), 0)
+				for _, item := range v {
+					writeDefault(w, items, item)
+				}
+			}
+			w.WriteBlockHeader(0, 0)
+		}
+	case Map:
+		var v map[string]any
+		if v, ok = def.(map[string]any); ok {
+			values := schema.(*MapSchema).Values()
+			if len(v) > 0 {
+				w.WriteBlockHeader(int64(len(v)), 0)
+				for k, value := range v {
+					w.WriteString(k)
+					writeDefault(w, values, value)
+				}
+			}
+			w.WriteBlockHeader(0, 0)
+		}
+	case Record:
+		var v map[string]any
+		if v, ok = def.(map[string]any); ok {
+			for _, field := range schema.(*RecordSchema).Fields() {
+				writeDefault(w, field.Type(), v[field.Name()])
+			}
+		}
+	case Union:
+		ok = true
+		w.WriteLong(0)
+		writeDefault(w, schema.(*UnionSchema).Types()[0], def)
+	}
+
+	if !ok && w.Error == nil {
+		w.Error = fmt.Errorf("%+v is not a %s", def, schema.Type())
+	}
+}
+
+// bytesOfDefault converts the default of a bytes or fixed schema, where each
+// code point of the string is a byte, to a byte slice. It returns false if a
+// code point is above U+00FF.
+func bytesOfDefault(s string) ([]byte, bool) {
+	b := make([]byte, 0, len(s))
+	for _, c := range s {
+		if c > 0xff {
+			return nil, false
+		}
+		b = append(b, byte(c))
+	}
+	return b, true
+}
@@ -11,13 +11,16 @@ import (
 )
 
 func createDecoderOfEnum(schema Schema, typ reflect2.Type) ValDecoder {
+	enum := schema.(*EnumSchema)
 	switch {
 	case typ.Kind() == reflect.String:
-		return &enumCodec{symbols: schema.(*EnumSchema).Symbols()}
+		return &enumCodec{symbols: enum.Symbols()}
 	case typ.Implements(textUnmarshalerType):
-		return &enumTextMarshalerCodec{typ: typ, symbols: schema.(*EnumSchema).Symbols()}
+		return &enumTextMarshalerCodec{typ: typ, symbols: enum.Symbols()}
 	case reflect2.PtrTo(typ).Implements(textUnmarshalerType):
-		return &enumTextMarshalerCodec{typ: typ, symbols: schema.(*EnumSchema).Symbols(), ptr: true}
+		return &enumTextMarshalerCodec{typ: typ, symbols: enum.Symbols(), ptr: true}
+	case isEnumIndexKind(typ.Kind()):
+		return &enumIndexCodec{typ: typ, symbols: enum.Symbols()}
 	}
 
 	return &errorDecoder{err: fmt.Errorf("avro: %s is unsupported for Avro %s", typ.String(), schema.Type())}
@@ -31,11 +34,15 @@ func createEncoderOfEnum(schema Schema, typ reflect2.Type) ValEncoder {
 		return &enumTextMarshalerCodec{typ: typ, symbols: schema.(*EnumSchema).Symbols()}
 	case reflect2.PtrTo(typ).Implements(textMarshalerType):
 		return &enumTextMarshalerCodec{typ: typ, symbols: schema.(*EnumSchema).Symbols(), ptr: true}
+	case isEnumIndexKind(typ.Kind()):
+		return &enumIndexCodec{typ: typ, symbols: schema.(*EnumSchema).Symbols()}
 	}
 
 	return &errorEncoder{err: fmt.Errorf("avro: %s is unsupported for Avro %s", typ.String(), schema.Type())}
 }
 
+// enumCodec encodes string enums. The default of the enum replaces unknown symbols only when resolving
+// a writer schema, see decoderOfResolvedEnum, as an unknown index is otherwise corrupt data.
 type enumCodec struct {
 	symbols []string
 }
@@ -78,6 +85,7 @@ func (c *enumTextMarshalerCodec) Decode(ptr unsafe.Pointer, r *Reader) {
 		r.ReportError("decode enum symbol", "unknown enum symbol")
 		return
 	}
+	symbol := c.symbols[i]
 
 	var obj any
 	if c.ptr {
@@ -92,7 +100,7 @@ func (c *enumTextMarshalerCodec) Decode(ptr unsafe.Pointer, r *Reader) {
 		obj = c.typ.UnsafeIndirect(ptr)
 	}
 	unmarshaler := (obj).(encoding.TextUnmarshaler)
-	if err := unmarshaler.UnmarshalText([]byte(c.symbols[i])); err != nil {
+	if err := unmarshaler.UnmarshalText([]byte(symbol)); err != nil {
 		r.ReportError("decode enum text unmarshaler", err.Error())
 	}
 }
@@ -127,3 +135,55 @@ func (c *enumTextMarshalerCodec) Encode(ptr unsafe.Pointer, w *Writer) {
 
 	w.Error = fmt.Errorf("avro: unknown enum symbol: %s", str)
 }
+
+// isEnumIndexKind determines if values of the kind are the indexes of enum symbols.
+func isEnumIndexKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// enumIndexCodec encodes integer enums, such as iota constants, whose values are the indexes of their symbols.
+type enumIndexCodec struct {
+	typ     reflect2.Type
+	symbols []string
+}
+
+func (c *enumIndexCodec) Decode(ptr unsafe.Pointer, r *Reader) {
+	i := int(r.ReadInt())
+
+	if i < 0 || i >= len(c.symbols) {
+		r.ReportError("decode enum symbol", "unknown enum symbol")
+		return
+	}
+	setEnumIndex(c.typ, ptr, i)
+}
+
+func (c *enumIndexCodec) Encode(ptr unsafe.Pointer, w *Writer) {
+	val := reflect.NewAt(c.typ.Type1(), ptr).Elem()
+	i := int64(-1)
+	if val.CanInt() {
+		i = val.Int()
+	} else if u := val.Uint(); u < uint64(len(c.symbols)) {
+		i = int64(u)
+	}
+	if i < 0 || i >= int64(len(c.symbols)) {
+		w.Error = fmt.Errorf("avro: unknown enum index: %s(%d)", c.typ.String(), val.Interface())
+		return
+	}
+
+	w.WriteInt(int32(i))
+}
+
+// setEnumIndex sets the integer enum of the type at ptr to the index.
+func setEnumIndex(typ reflect2.Type, ptr unsafe.Pointer, i int) {
+	val := reflect.NewAt(typ.Type1(), ptr).Elem()
+	if val.CanInt() {
+		val.SetInt(int64(i))
+		return
+	}
+	val.SetUint(uint64(i))
+}
@@ -20,13 +20,24 @@ func createDecoderOfFixed(schema Schema, typ reflect2.Type) ValDecoder {
 		}
 		return &fixedCodec{arrayType: typ.(*reflect2.UnsafeArrayType)}
 
-	case reflect.Uint64:
-		fixed := schema.(*FixedSchema)
+	case reflect.Uint, reflect.Uint64:
 		if fixed.Size() != 8 {
 			break
 		}
+		return createCodecOfUint(schema, typ)
 
-		return &fixedUint64Codec{}
+	case reflect.Int64, reflect.String:
+		if dec, ok := fixed.Logical().(*DecimalLogicalSchema); ok {
+			return createCodecOfDecimal(dec, fixed.Size(), typ)
+		}
+
+	case reflect.Ptr:
+		elemType := typ.(*reflect2.UnsafePtrType).Elem()
+		dec, ok := fixed.Logical().(*DecimalLogicalSchema)
+		if !ok || elemType.Kind() != reflect.Struct || !elemType.Type1().ConvertibleTo(ratType) {
+			break
+		}
+		return &fixedDecimalPtrCodec{prec: dec.Precision(), scale: dec.Scale(), size: fixed.Size()}
 
 	case reflect.Struct:
 		ls := fixed.Logical()
@@ -61,13 +72,16 @@ func createEncoderOfFixed(schema Schema, typ reflect2.Type) ValEncoder {
 		}
 		return &fixedCodec{arrayType: typ.(*reflect2.UnsafeArrayType)}
 
-	case reflect.Uint64:
-		fixed := schema.(*FixedSchema)
+	case reflect.Uint, reflect.Uint64:
 		if fixed.Size() != 8 {
 			break
 		}
+		return createCodecOfUint(schema, typ)
 
-		return &fixedUint64Codec{}
+	case reflect.Int64, reflect.String:
+		if dec, ok := fixed.Logical().(*DecimalLogicalSchema); ok {
+			return createCodecOfDecimal(dec, fixed.Size(), typ)
+		}
 
 	case reflect.Ptr:
 		ptrType := typ.(*reflect2.UnsafePtrType)
@@ -80,7 +94,7 @@ func createEncoderOfFixed(schema Schema, typ reflect2.Type) ValEncoder {
 			break
 		}
 		dec := ls.(*DecimalLogicalSchema)
-		return &fixedDecimalCodec{prec: dec.Precision(), scale: dec.Scale(), size: fixed.Size()}
+		return &fixedDecimalPtrCodec{prec: dec.Precision(), scale: dec.Scale(), size: fixed.Size()}
 
 	case reflect.Struct:
 		ls := fixed.Logical()
@@ -88,8 +102,12 @@ func createEncoderOfFixed(schema Schema, typ reflect2.Type) ValEncoder {
 			break
 		}
 		typ1 := typ.Type1()
-		if typ1.ConvertibleTo(durType) && ls.Type() == Duration {
+		switch {
+		case typ1.ConvertibleTo(durType) && ls.Type() == Duration:
 			return &fixedDurationCodec{}
+		case typ1.ConvertibleTo(ratType) && ls.Type() == Decimal:
+			dec := ls.(*DecimalLogicalSchema)
+			return &fixedDecimalCodec{prec: dec.Precision(), scale: dec.Scale(), size: fixed.Size()}
 		}
 	default:
 		break
@@ -100,20 +118,6 @@ func createEncoderOfFixed(schema Schema, typ reflect2.Type) ValEncoder {
 	}
 }
 
-type fixedUint64Codec [8]byte
-
-func (c *fixedUint64Codec) Decode(ptr unsafe.Pointer, r *Reader) {
-	buffer := c[:]
-	r.Read(buffer)
-	*(*uint64)(ptr) = binary.BigEndian.Uint64(buffer)
-}
-
-func (c *fixedUint64Codec) Encode(ptr unsafe.Pointer, w *Writer) {
-	buffer := c[:]
-	binary.BigEndian.PutUint64(buffer, *(*uint64)(ptr))
-	_, _ = w.Write(buffer)
-}
-
 type fixedCodec struct {
 	arrayType *reflect2.UnsafeArrayType
 }
@@ -144,32 +148,28 @@ func (c *fixedDecimalCodec) Decode(ptr unsafe.Pointer, r *Reader) {
 }
 
 func (c *fixedDecimalCodec) Encode(ptr unsafe.Pointer, w *Writer) {
-	r := *((**big.Rat)(ptr))
-	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(c.scale)), nil)
-	i := (&big.Int{}).Mul(r.Num(), scale)
-	i = i.Div(i, r.Denom())
-
-	var b []byte
-	switch i.Sign() {
-	case 0:
-		b = make([]byte, c.size)
-
-	case 1:
-		b = i.Bytes()
-		if b[0]&0x80 > 0 {
-			b = append([]byte{0}, b...)
-		}
-		if len(b) < c.size {
-			padded := make([]byte, c.size)
-			copy(padded[c.size-len(b):], b)
-			b = padded
-		}
+	writeDecimal(w, (*big.Rat)(ptr), c.prec, c.scale, c.size)
+}
 
-	case -1:
-		b = i.Add(i, (&big.Int{}).Lsh(one, uint(c.size*8))).Bytes()
-	}
+type fixedDecimalPtrCodec struct {
+	prec  int
+	scale int
+	size  int
+}
 
-	_, _ = w.Write(b)
+func (c *fixedDecimalPtrCodec) Decode(ptr unsafe.Pointer, r *Reader) {
+	b := make([]byte, c.size)
+	r.Read(b)
+	*((**big.Rat)(ptr)) = ratFromBytes(b, c.scale)
+}
+
+func (c *fixedDecimalPtrCodec) Encode(ptr unsafe.Pointer, w *Writer) {
+	r := *((**big.Rat)(ptr))
+	if r == nil {
+		w.Error = errNilDecimal
+		return
+	}
+	writeDecimal(w, r, c.prec, c.scale, c.size)
 }
 
 type fixedDurationCodec struct{}
@@ -0,0 +1,446 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// The Avro JSON encoding is transcoded from and to the binary encoding, so values are
+// encoded and decoded by the same codecs and struct descriptors as the binary encoding.
+
+var jsonAPI = jsoniter.ConfigCompatibleWithStandardLibrary
+
+func (c *frozenConfig) MarshalAvroJSON(schema Schema, v any) ([]byte, error) {
+	data, err := c.Marshal(schema, v)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := jsonAPI.BorrowStream(nil)
+	defer jsonAPI.ReturnStream(stream)
+
+	reader := c.borrowReader(data)
+	writeJSON(stream, schema, reader)
+	err = reader.Error
+	c.returnReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if stream.Error != nil {
+		return nil, stream.Error
+	}
+
+	result := stream.Buffer()
+	copied := make([]byte, len(result))
+	copy(copied, result)
+	return copied, nil
+}
+
+func (c *frozenConfig) UnmarshalAvroJSON(schema Schema, data []byte, v any) error {
+	iter := jsonAPI.BorrowIterator(data)
+	defer jsonAPI.ReturnIterator(iter)
+
+	return c.decodeJSON(schema, iter, v)
+}
+
+func (c *frozenConfig) decodeJSON(schema Schema, iter *jsoniter.Iterator, v any) error {
+	writer := c.borrowWriter()
+	defer c.returnWriter(writer)
+
+	readJSON(iter, schema, writer)
+	if iter.Error != nil && !errors.Is(iter.Error, io.EOF) {
+		return iter.Error
+	}
+	if writer.Error != nil {
+		return writer.Error
+	}
+
+	return c.Unmarshal(schema, writer.Buffer(), v)
+}
+
+func (c *frozenConfig) NewJSONEncoder(schema Schema, w io.Writer) *JSONEncoder {
+	return &JSONEncoder{
+		cfg: c,
+		s:   schema,
+		w:   w,
+	}
+}
+
+func (c *frozenConfig) NewJSONDecoder(schema Schema, r io.Reader) *JSONDecoder {
+	return &JSONDecoder{
+		cfg:  c,
+		s:    schema,
+		iter: jsoniter.Parse(jsonAPI, r, 512),
+	}
+}
+
+// JSONEncoder writes Avro JSON encoded values to an output stream.
+type JSONEncoder struct {
+	cfg *frozenConfig
+	s   Schema
+	w   io.Writer
+}
+
+// NewJSONEncoder returns a new JSON encoder that writes to w using schema.
+func NewJSONEncoder(schema Schema, w io.Writer) *JSONEncoder {
+	return DefaultConfig.NewJSONEncoder(schema, w)
+}
+
+// Encode writes the Avro JSON encoding of v, followed by a newline, to the stream.
+func (e *JSONEncoder) Encode(v any) error {
+	p, err := e.cfg.MarshalAvroJSON(e.s, v)
+	if err != nil {
+		return err
+	}
+	p = append(p, '\n')
+	_, err = e.w.Write(p)
+	return err
+}
+
+// JSONDecoder reads and decodes Avro JSON encoded values from an input stream.
+type JSONDecoder struct {
+	cfg  *frozenConfig
+	s    Schema
+	iter *jsoniter.Iterator
+}
+
+// NewJSONDecoder returns a new JSON decoder that reads from r using schema.
+func NewJSONDecoder(schema Schema, r io.Reader) *JSONDecoder {
+	return DefaultConfig.NewJSONDecoder(schema, r)
+}
+
+// Decode reads the next Avro JSON encoded value from its input and stores it in the value pointed to by v.
+// It returns io.EOF when there are no more values.
+func (d *JSONDecoder) Decode(v any) error {
+	if d.iter.WhatIsNext() == jsoniter.InvalidValue {
+		if d.iter.Error == nil || errors.Is(d.iter.Error, io.EOF) {
+			return io.EOF
+		}
+		return d.iter.Error
+	}
+
+	return d.cfg.decodeJSON(d.s, d.iter, v)
+}
+
+// MarshalJSON returns the Avro JSON encoding of v.
+func MarshalJSON(schema Schema, v any) ([]byte, error) {
+	return DefaultConfig.MarshalAvroJSON(schema, v)
+}
+
+// UnmarshalJSON parses the Avro JSON encoded data and stores the result in the value pointed to by v.
+func UnmarshalJSON(schema Schema, data []byte, v any) error {
+	return DefaultConfig.UnmarshalAvroJSON(schema, data, v)
+}
+
+// writeJSON transcodes the binary encoded value of schema from the reader to JSON.
+func writeJSON(s *jsoniter.Stream, schema Schema, r *Reader) {
+	if r.Error != nil {
+		return
+	}
+
+	switch schema.Type() {
+	case Ref:
+		writeJSON(s, schema.(*RefSchema).Schema(), r)
+
+	case Null:
+		s.WriteNil()
+
+	case Boolean:
+		s.WriteBool(r.ReadBool())
+
+	case Int:
+		s.WriteInt32(r.ReadInt())
+
+	case Long:
+		s.WriteInt64(r.ReadLong())
+
+	case Float:
+		s.WriteFloat32(r.ReadFloat())
+
+	case Double:
+		s.WriteFloat64(r.ReadDouble())
+
+	case String:
+		s.WriteString(r.ReadString())
+
+	case Bytes, Raw:
+		s.WriteString(stringOfBytes(r.ReadBytes()))
+
+	case Fixed:
+		b := make([]byte, schema.(*FixedSchema).Size())
+		r.Read(b)
+		s.WriteString(stringOfBytes(b))
+
+	case Enum:
+		symbols := schema.(*EnumSchema).Symbols()
+		i := int(r.ReadInt())
+		if i < 0 || i >= len(symbols) {
+			r.ReportError("write json enum", "unknown enum symbol")
+			return
+		}
+		s.WriteString(symbols[i])
+
+	case Array:
+		items := schema.(*ArraySchema).Items()
+		s.WriteArrayStart()
+		first := true
+		for {
+			l, _ := r.ReadBlockHeader()
+			if l == 0 || r.Error != nil {
+				break
+			}
+			for i := int64(0); i < l; i++ {
+				if !first {
+					s.WriteMore()
+				}
+				first = false
+				writeJSON(s, items, r)
+			}
+		}
+		s.WriteArrayEnd()
+
+	case Map:
+		values := schema.(*MapSchema).Values()
+		s.WriteObjectStart()
+		first := true
+		for {
+			l, _ := r.ReadBlockHeader()
+			if l == 0 || r.Error != nil {
+				break
+			}
+			for i := int64(0); i < l; i++ {
+				if !first {
+					s.WriteMore()
+				}
+				first = false
+				s.WriteObjectField(r.ReadString())
+				writeJSON(s, values, r)
+			}
+		}
+		s.WriteObjectEnd()
+
+	case Record:
+		s.WriteObjectStart()
+		for i, field := range schema.(*RecordSchema).Fields() {
+			if i > 0 {
+				s.WriteMore()
+			}
+			s.WriteObjectField(field.Name())
+			writeJSON(s, field.Type(), r)
+		}
+		s.WriteObjectEnd()
+
+	case Union:
+		_, typ := getUnionSchema(schema.(*UnionSchema), r)
+		if typ == nil {
+			return
+		}
+		if typ.Type() == Null {
+			s.WriteNil()
+			return
+		}
+		s.WriteObjectStart()
+		s.WriteObjectField(unionJSONName(typ))
+		writeJSON(s, typ, r)
+		s.WriteObjectEnd()
+
+	default:
+		r.ReportError("write json", fmt.Sprintf("schema type %s is unsupported", schema.Type()))
+	}
+}
+
+// readJSON transcodes the JSON encoded value of schema from the iterator to the writer.
+func readJSON(iter *jsoniter.Iterator, schema Schema, w *Writer) {
+	if iter.Error != nil || w.Error != nil {
+		return
+	}
+
+	switch schema.Type() {
+	case Ref:
+		readJSON(iter, schema.(*RefSchema).Schema(), w)
+
+	case Null:
+		if !iter.ReadNil() {
+			iter.ReportError("read json null", "expected null")
+		}
+
+	case Boolean:
+		w.WriteBool(iter.ReadBool())
+
+	case Int:
+		w.WriteInt(iter.ReadInt32())
+
+	case Long:
+		w.WriteLong(iter.ReadInt64())
+
+	case Float:
+		w.WriteFloat(iter.ReadFloat32())
+
+	case Double:
+		w.WriteDouble(iter.ReadFloat64())
+
+	case String:
+		w.WriteString(iter.ReadString())
+
+	case Bytes, Raw:
+		b, ok := bytesOfDefault(iter.ReadString())
+		if !ok {
+			iter.ReportError("read json bytes", "code points above U+00FF are not bytes")
+			return
+		}
+		w.WriteBytes(b)
+
+	case Fixed:
+		b, ok := bytesOfDefault(iter.ReadString())
+		if !ok {
+			iter.ReportError("read json fixed", "code points above U+00FF are not bytes")
+			return
+		}
+		if len(b) != schema.(*FixedSchema).Size() {
+			iter.ReportError("read json fixed", fmt.Sprintf("expected %d bytes", schema.(*FixedSchema).Size()))
+			return
+		}
+		_, _ = w.Write(b)
+
+	case Enum:
+		sym := iter.ReadString()
+		for i, symbol := range schema.(*EnumSchema).Symbols() {
+			if symbol == sym {
+				w.WriteInt(int32(i))
+				return
+			}
+		}
+		iter.ReportError("read json enum", "unknown enum symbol "+sym)
+
+	case Array:
+		items := schema.(*ArraySchema).Items()
+		length := w.WriteBlockCB(func(w *Writer) int64 {
+			count := int64(0)
+			iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+				readJSON(iter, items, w)
+				count++
+				return iter.Error == nil && w.Error == nil
+			})
+			return count
+		})
+		if length > 0 {
+			w.WriteBlockHeader(0, 0)
+		}
+
+	case Map:
+		values := schema.(*MapSchema).Values()
+		length := w.WriteBlockCB(func(w *Writer) int64 {
+			count := int64(0)
+			iter.ReadMapCB(func(iter *jsoniter.Iterator, key string) bool {
+				w.WriteString(key)
+				readJSON(iter, values, w)
+				count++
+				return iter.Error == nil && w.Error == nil
+			})
+			return count
+		})
+		if length > 0 {
+			w.WriteBlockHeader(0, 0)
+		}
+
+	case Record:
+		// The fields can be in any order, they are written in the schema order.
+		raws := make(map[string][]byte)
+		iter.ReadMapCB(func(iter *jsoniter.Iterator, key string) bool {
+			raws[key] = append([]byte(nil), iter.SkipAndReturnBytes()...)
+			return iter.Error == nil
+		})
+		for _, field := range schema.(*RecordSchema).Fields() {
+			raw, ok := raws[field.Name()]
+			if !ok {
+				if !field.HasDefault() {
+					iter.ReportError("read json record", "missing field "+field.Name())
+					return
+				}
+				writeDefault(w, field.Type(), field.Default())
+				continue
+			}
+			sub := jsonAPI.BorrowIterator(raw)
+			readJSON(sub, field.Type(), w)
+			err := sub.Error
+			jsonAPI.ReturnIterator(sub)
+			if err != nil && !errors.Is(err, io.EOF) {
+				iter.ReportError("read json record", fmt.Sprintf("field %s: %v", field.Name(), err))
+				return
+			}
+		}
+
+	case Union:
+		union := schema.(*UnionSchema)
+		if iter.WhatIsNext() == jsoniter.NilValue {
+			iter.ReadNil()
+			_, idx := union.Types().Get(string(Null))
+			if idx < 0 {
+				iter.ReportError("read json union", "union is not nullable")
+				return
+			}
+			w.WriteLong(int64(idx))
+			return
+		}
+		name := iter.ReadObject()
+		if name == "" {
+			iter.ReportError("read json union", "expected a union branch")
+			return
+		}
+		typ, idx := unionBranchOfJSONName(union, name)
+		if typ == nil {
+			iter.ReportError("read json union", "unknown union type "+name)
+			return
+		}
+		w.WriteLong(int64(idx))
+		readJSON(iter, typ, w)
+		if iter.ReadObject() != "" {
+			iter.ReportError("read json union", "expected a single union branch")
+		}
+
+	default:
+		iter.ReportError("read json", fmt.Sprintf("schema type %s is unsupported", schema.Type()))
+	}
+}
+
+// unionJSONName returns the name of a union branch in the JSON encoding, which is
+// the full name of named types and the type name otherwise.
+func unionJSONName(schema Schema) string {
+	if schema.Type() == Ref {
+		schema = schema.(*RefSchema).Schema()
+	}
+	if n, ok := schema.(NamedSchema); ok {
+		return n.FullName()
+	}
+	return string(schema.Type())
+}
+
+func unionBranchOfJSONName(union *UnionSchema, name string) (Schema, int) {
+	for i, typ := range union.Types() {
+		if unionJSONName(typ) == name {
+			return typ, i
+		}
+	}
+	for i, typ := range union.Types() {
+		if typ.Type() == Ref {
+			typ = typ.(*RefSchema).Schema()
+		}
+		if n, ok := typ.(NamedSchema); ok && n.Name() == name {
+			return union.Types()[i], i
+		}
+	}
+	return nil, -1
+}
+
+// stringOfBytes converts bytes to a string where each byte is a code point, as
+// bytes and fixed values are encoded in JSON.
+func stringOfBytes(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strconv"
 	"unsafe"
 
 	"github.com/modern-go/reflect2"
@@ -17,8 +18,10 @@ func createDecoderOfMap(cfg *frozenConfig, schema Schema, typ reflect2.Type) Val
 		switch {
 		case keyType.Kind() == reflect.String:
 			return decoderOfMap(cfg, schema, typ)
-		case keyType.Implements(textUnmarshalerType):
+		case keyType.Implements(textUnmarshalerType) || reflect2.PtrTo(keyType).Implements(textUnmarshalerType):
 			return decoderOfMapUnmarshaler(cfg, schema, typ)
+		case isIntKind(keyType.Kind()):
+			return decoderOfMapIntKey(cfg, schema, typ)
 		}
 	}
 
@@ -33,6 +36,8 @@ func createEncoderOfMap(cfg *frozenConfig, schema Schema, typ reflect2.Type) Val
 			return encoderOfMap(cfg, schema, typ)
 		case keyType.Implements(textMarshalerType):
 			return encoderOfMapMarshaler(cfg, schema, typ)
+		case isIntKind(keyType.Kind()):
+			return encoderOfMapIntKey(cfg, schema, typ)
 		}
 	}
 
@@ -87,19 +92,27 @@ func decoderOfMapUnmarshaler(cfg *frozenConfig, schema Schema, typ reflect2.Type
 	mapType := typ.(*reflect2.UnsafeMapType)
 	decoder := decoderOfType(cfg, m.Values(), mapType.Elem())
 
+	// Keys unmarshaled by their pointer
+	var ptrKeyType reflect2.Type
+	if !mapType.Key().Implements(textUnmarshalerType) {
+		ptrKeyType = reflect2.PtrTo(mapType.Key())
+	}
+
 	return &mapDecoderUnmarshaler{
-		mapType:  mapType,
-		keyType:  mapType.Key(),
-		elemType: mapType.Elem(),
-		decoder:  decoder,
+		mapType:    mapType,
+		keyType:    mapType.Key(),
+		ptrKeyType: ptrKeyType,
+		elemType:   mapType.Elem(),
+		decoder:    decoder,
 	}
 }
 
 type mapDecoderUnmarshaler struct {
-	mapType  *reflect2.UnsafeMapType
-	keyType  reflect2.Type
-	elemType reflect2.Type
-	decoder  ValDecoder
+	mapType    *reflect2.UnsafeMapType
+	keyType    reflect2.Type
+	ptrKeyType reflect2.Type
+	elemType   reflect2.Type
+	decoder    ValDecoder
 }
 
 func (d *mapDecoderUnmarshaler) Decode(ptr unsafe.Pointer, r *Reader) {
@@ -115,12 +128,17 @@ func (d *mapDecoderUnmarshaler) Decode(ptr unsafe.Pointer, r *Reader) {
 
 		for i := int64(0); i < l; i++ {
 			keyPtr := d.keyType.UnsafeNew()
-			keyObj := d.keyType.UnsafeIndirect(keyPtr)
-			if reflect2.IsNil(keyObj) {
-				ptrType := d.keyType.(*reflect2.UnsafePtrType)
-				newPtr := ptrType.Elem().UnsafeNew()
-				*((*unsafe.Pointer)(keyPtr)) = newPtr
+			var keyObj any
+			if d.ptrKeyType != nil {
+				keyObj = d.ptrKeyType.UnsafeIndirect(unsafe.Pointer(&keyPtr))
+			} else {
 				keyObj = d.keyType.UnsafeIndirect(keyPtr)
+				if reflect2.IsNil(keyObj) {
+					ptrType := d.keyType.(*reflect2.UnsafePtrType)
+					newPtr := ptrType.Elem().UnsafeNew()
+					*((*unsafe.Pointer)(keyPtr)) = newPtr
+					keyObj = d.keyType.UnsafeIndirect(keyPtr)
+				}
 			}
 			unmarshaler := keyObj.(encoding.TextUnmarshaler)
 			err := unmarshaler.UnmarshalText([]byte(r.ReadString()))
@@ -244,3 +262,237 @@ func (e *mapEncoderMarshaller) Encode(ptr unsafe.Pointer, w *Writer) {
 		w.Error = fmt.Errorf("%v: %w", e.mapType, w.Error)
 	}
 }
+
+func decoderOfMapIntKey(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValDecoder {
+	m := schema.(*MapSchema)
+	mapType := typ.(*reflect2.UnsafeMapType)
+	decoder := decoderOfType(cfg, m.Values(), mapType.Elem())
+
+	return &mapIntKeyDecoder{
+		mapType:  mapType,
+		keyType:  mapType.Key(),
+		elemType: mapType.Elem(),
+		decoder:  decoder,
+	}
+}
+
+type mapIntKeyDecoder struct {
+	mapType  *reflect2.UnsafeMapType
+	keyType  reflect2.Type
+	elemType reflect2.Type
+	decoder  ValDecoder
+}
+
+func (d *mapIntKeyDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
+	if d.mapType.UnsafeIsNil(ptr) {
+		d.mapType.UnsafeSet(ptr, d.mapType.UnsafeMakeMap(0))
+	}
+
+	for {
+		l, _ := r.ReadBlockHeader()
+		if l == 0 {
+			break
+		}
+
+		for i := int64(0); i < l; i++ {
+			keyPtr := d.keyType.UnsafeNew()
+			if err := parseIntKey(d.keyType.Type1(), r.ReadString(), keyPtr); err != nil {
+				r.ReportError("mapIntKeyDecoder", err.Error())
+				return
+			}
+
+			elemPtr := d.elemType.UnsafeNew()
+			d.decoder.Decode(elemPtr, r)
+
+			d.mapType.UnsafeSetIndex(ptr, keyPtr, elemPtr)
+		}
+	}
+
+	if r.Error != nil && !errors.Is(r.Error, io.EOF) {
+		r.Error = fmt.Errorf("%v: %w", d.mapType, r.Error)
+	}
+}
+
+func encoderOfMapIntKey(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
+	m := schema.(*MapSchema)
+	mapType := typ.(*reflect2.UnsafeMapType)
+	encoder := encoderOfType(cfg, m.Values(), mapType.Elem())
+
+	return &mapIntKeyEncoder{
+		blockLength: cfg.getBlockLength(),
+		mapType:     mapType,
+		keyType:     mapType.Key().Type1(),
+		encoder:     encoder,
+	}
+}
+
+type mapIntKeyEncoder struct {
+	blockLength int
+	mapType     *reflect2.UnsafeMapType
+	keyType     reflect.Type
+	encoder     ValEncoder
+}
+
+func (e *mapIntKeyEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
+	blockLength := e.blockLength
+
+	iter := e.mapType.UnsafeIterate(ptr)
+
+	for {
+		wrote := w.WriteBlockCB(func(w *Writer) int64 {
+			var i int
+			for i = 0; iter.HasNext() && i < blockLength; i++ {
+				keyPtr, elemPtr := iter.UnsafeNext()
+				w.WriteString(formatIntKey(e.keyType, keyPtr))
+				e.encoder.Encode(elemPtr, w)
+			}
+
+			return int64(i)
+		})
+
+		if wrote == 0 {
+			break
+		}
+	}
+
+	if w.Error != nil && !errors.Is(w.Error, io.EOF) {
+		w.Error = fmt.Errorf("%v: %w", e.mapType, w.Error)
+	}
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func formatIntKey(typ reflect.Type, ptr unsafe.Pointer) string {
+	v := reflect.NewAt(typ, ptr).Elem()
+	if v.CanInt() {
+		return strconv.FormatInt(v.Int(), 10)
+	}
+	return strconv.FormatUint(v.Uint(), 10)
+}
+
+func parseIntKey(typ reflect.Type, s string, ptr unsafe.Pointer) error {
+	v := reflect.NewAt(typ, ptr).Elem()
+	if v.CanInt() {
+		i, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+		return nil
+	}
+	u, err := strconv.ParseUint(s, 10, typ.Bits())
+	if err != nil {
+		return err
+	}
+	v.SetUint(u)
+	return nil
+}
+
+func decoderOfMapEntries(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValDecoder {
+	entry := recordOf(schema.(*ArraySchema).Items())
+	mapType := typ.(*reflect2.UnsafeMapType)
+
+	return &mapEntriesDecoder{
+		mapType:    mapType,
+		keyType:    mapType.Key(),
+		elemType:   mapType.Elem(),
+		keyDecoder: decoderOfType(cfg, entry.Fields()[0].Type(), mapType.Key()),
+		decoder:    decoderOfType(cfg, entry.Fields()[1].Type(), mapType.Elem()),
+	}
+}
+
+type mapEntriesDecoder struct {
+	mapType    *reflect2.UnsafeMapType
+	keyType    reflect2.Type
+	elemType   reflect2.Type
+	keyDecoder ValDecoder
+	decoder    ValDecoder
+}
+
+func (d *mapEntriesDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
+	if d.mapType.UnsafeIsNil(ptr) {
+		d.mapType.UnsafeSet(ptr, d.mapType.UnsafeMakeMap(0))
+	}
+
+	for {
+		l, _ := r.ReadBlockHeader()
+		if l == 0 {
+			break
+		}
+
+		for i := int64(0); i < l; i++ {
+			keyPtr := d.keyType.UnsafeNew()
+			d.keyDecoder.Decode(keyPtr, r)
+
+			elemPtr := d.elemType.UnsafeNew()
+			d.decoder.Decode(elemPtr, r)
+
+			d.mapType.UnsafeSetIndex(ptr, keyPtr, elemPtr)
+		}
+	}
+
+	if r.Error != nil && !errors.Is(r.Error, io.EOF) {
+		r.Error = fmt.Errorf("%v: %w", d.mapType, r.Error)
+	}
+}
+
+func encoderOfMapEntries(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
+	entry := recordOf(schema.(*ArraySchema).Items())
+	mapType := typ.(*reflect2.UnsafeMapType)
+
+	return &mapEntriesEncoder{
+		blockLength: cfg.getBlockLength(),
+		mapType:     mapType,
+		keyEncoder:  encoderOfType(cfg, entry.Fields()[0].Type(), mapType.Key()),
+		encoder:     encoderOfType(cfg, entry.Fields()[1].Type(), mapType.Elem()),
+	}
+}
+
+type mapEntriesEncoder struct {
+	blockLength int
+	mapType     *reflect2.UnsafeMapType
+	keyEncoder  ValEncoder
+	encoder     ValEncoder
+}
+
+func (e *mapEntriesEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
+	blockLength := e.blockLength
+
+	iter := e.mapType.UnsafeIterate(ptr)
+
+	for {
+		wrote := w.WriteBlockCB(func(w *Writer) int64 {
+			var i int
+			for i = 0; iter.HasNext() && i < blockLength; i++ {
+				keyPtr, elemPtr := iter.UnsafeNext()
+				e.keyEncoder.Encode(keyPtr, w)
+				e.encoder.Encode(elemPtr, w)
+			}
+
+			return int64(i)
+		})
+
+		if wrote == 0 {
+			break
+		}
+	}
+
+	if w.Error != nil && !errors.Is(w.Error, io.EOF) {
+		w.Error = fmt.Errorf("%v: %w", e.mapType, w.Error)
+	}
+}
+
+func recordOf(schema Schema) *RecordSchema {
+	if ref, ok := schema.(*RefSchema); ok {
+		schema = ref.Schema()
+	}
+	return schema.(*RecordSchema)
+}
@@ -13,11 +13,20 @@ var (
 )
 
 func createDecoderOfMarshaler(_ *frozenConfig, schema Schema, typ reflect2.Type) ValDecoder {
+	isUUID := getLogicalType(schema) == UUID
 	if typ.Implements(textUnmarshalerType) && schema.Type() == String {
+		if isUUID {
+			return &uuidTextCodec{typ}
+		}
 		return &textMarshalerCodec{typ}
 	}
 	ptrType := reflect2.PtrTo(typ)
 	if ptrType.Implements(textUnmarshalerType) && schema.Type() == String {
+		if isUUID {
+			return &referenceDecoder{
+				&uuidTextCodec{ptrType},
+			}
+		}
 		return &referenceDecoder{
 			&textMarshalerCodec{ptrType},
 		}
@@ -31,6 +40,12 @@ func createEncoderOfMarshaler(_ *frozenConfig, schema Schema, typ reflect2.Type)
 			typ: typ,
 		}
 	}
+	ptrType := reflect2.PtrTo(typ)
+	if ptrType.Implements(textMarshalerType) && schema.Type() == String {
+		return &referenceEncoder{
+			&textMarshalerCodec{ptrType},
+		}
+	}
 	return nil
 }
 
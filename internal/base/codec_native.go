@@ -2,6 +2,7 @@ package base
 
 import (
 	"fmt"
+	"math"
 	"math/big"
 	"reflect"
 	"time"
@@ -73,10 +74,18 @@ func createDecoderOfNative(schema Schema, typ reflect2.Type) ValDecoder {
 		case st == Long:
 			return &longCodec[int64]{}
 
+		case st == Bytes && lt == Decimal:
+			return createCodecOfDecimal(getLogicalSchema(schema).(*DecimalLogicalSchema), 0, typ)
+
 		default:
 			break
 		}
 
+	case reflect.Uint, reflect.Uint64:
+		if codec := createCodecOfUint(schema, typ); codec != nil {
+			return codec
+		}
+
 	case reflect.Float32:
 		if schema.Type() != Float {
 			break
@@ -90,11 +99,25 @@ func createDecoderOfNative(schema Schema, typ reflect2.Type) ValDecoder {
 		return &float64Codec{}
 
 	case reflect.String:
+		if schema.Type() == Bytes && getLogicalType(schema) == Decimal {
+			return createCodecOfDecimal(getLogicalSchema(schema).(*DecimalLogicalSchema), 0, typ)
+		}
 		if schema.Type() != String {
 			break
 		}
+		if getLogicalType(schema) == UUID {
+			return &uuidStringCodec{}
+		}
 		return &stringCodec{}
 
+	case reflect.Array:
+		arrayType := typ.(reflect2.ArrayType)
+		if schema.Type() != String || getLogicalType(schema) != UUID ||
+			arrayType.Elem().Kind() != reflect.Uint8 || arrayType.Len() != 16 {
+			break
+		}
+		return &uuidArrayCodec{}
+
 	case reflect.Slice:
 		if typ.(reflect2.SliceType).Elem().Kind() != reflect.Uint8 || schema.Type() != Bytes {
 			break
@@ -118,6 +141,18 @@ func createDecoderOfNative(schema Schema, typ reflect2.Type) ValDecoder {
 		case Istpy1Time && st == Long && lt == TimestampMicros:
 			return &timestampMicrosCodec{}
 
+		case Istpy1Time && st == Long && lt == TimestampNanos:
+			return &timestampNanosCodec{}
+
+		case Istpy1Time && st == Long && lt == LocalTimestampMillis:
+			return &timestampMillisCodec{local: true}
+
+		case Istpy1Time && st == Long && lt == LocalTimestampMicros:
+			return &timestampMicrosCodec{local: true}
+
+		case Istpy1Time && st == Long && lt == LocalTimestampNanos:
+			return &timestampNanosCodec{local: true}
+
 		case Istpy1Rat && st == Bytes && lt == Decimal:
 			dec := ls.(*DecimalLogicalSchema)
 
@@ -213,10 +248,18 @@ func createEncoderOfNative(schema Schema, typ reflect2.Type) ValEncoder {
 		case st == Long:
 			return &longCodec[int64]{}
 
+		case st == Bytes && lt == Decimal:
+			return createCodecOfDecimal(getLogicalSchema(schema).(*DecimalLogicalSchema), 0, typ)
+
 		default:
 			break
 		}
 
+	case reflect.Uint, reflect.Uint64:
+		if codec := createCodecOfUint(schema, typ); codec != nil {
+			return codec
+		}
+
 	case reflect.Float32:
 		switch schema.Type() {
 		case Double:
@@ -233,11 +276,25 @@ func createEncoderOfNative(schema Schema, typ reflect2.Type) ValEncoder {
 		return &float64Codec{}
 
 	case reflect.String:
+		if schema.Type() == Bytes && getLogicalType(schema) == Decimal {
+			return createCodecOfDecimal(getLogicalSchema(schema).(*DecimalLogicalSchema), 0, typ)
+		}
 		if schema.Type() != String {
 			break
 		}
+		if getLogicalType(schema) == UUID {
+			return &uuidStringCodec{}
+		}
 		return &stringCodec{}
 
+	case reflect.Array:
+		arrayType := typ.(reflect2.ArrayType)
+		if schema.Type() != String || getLogicalType(schema) != UUID ||
+			arrayType.Elem().Kind() != reflect.Uint8 || arrayType.Len() != 16 {
+			break
+		}
+		return &uuidArrayCodec{}
+
 	case reflect.Slice:
 		if typ.(reflect2.SliceType).Elem().Kind() != reflect.Uint8 || schema.Type() != Bytes {
 			break
@@ -259,6 +316,18 @@ func createEncoderOfNative(schema Schema, typ reflect2.Type) ValEncoder {
 		case isTpy1Time && st == Long && lt == TimestampMicros:
 			return &timestampMicrosCodec{}
 
+		case isTpy1Time && st == Long && lt == TimestampNanos:
+			return &timestampNanosCodec{}
+
+		case isTpy1Time && st == Long && lt == LocalTimestampMillis:
+			return &timestampMillisCodec{local: true}
+
+		case isTpy1Time && st == Long && lt == LocalTimestampMicros:
+			return &timestampMicrosCodec{local: true}
+
+		case isTpy1Time && st == Long && lt == LocalTimestampNanos:
+			return &timestampNanosCodec{local: true}
+
 		case isTpy1Rat && st != Bytes || lt == Decimal:
 			ls := getLogicalSchema(schema)
 			dec := ls.(*DecimalLogicalSchema)
@@ -417,34 +486,81 @@ func (c *dateCodec) Encode(ptr unsafe.Pointer, w *Writer) {
 	w.WriteInt(int32(days))
 }
 
-type timestampMillisCodec struct{}
+// timestampMillisCodec encodes the milliseconds since the epoch, or the local wall clock
+// as if it was UTC when local is set.
+type timestampMillisCodec struct {
+	local bool
+}
 
 func (c *timestampMillisCodec) Decode(ptr unsafe.Pointer, r *Reader) {
 	i := r.ReadLong()
 	sec := i / 1e3
 	nsec := (i - sec*1e3) * 1e6
-	*((*time.Time)(ptr)) = time.Unix(sec, nsec).UTC()
+	*((*time.Time)(ptr)) = fromTimestamp(time.Unix(sec, nsec), c.local)
 }
 
 func (c *timestampMillisCodec) Encode(ptr unsafe.Pointer, w *Writer) {
-	t := *((*time.Time)(ptr))
+	t := toTimestamp(*((*time.Time)(ptr)), c.local)
 	w.WriteLong(t.Unix()*1e3 + int64(t.Nanosecond()/1e6))
 }
 
-type timestampMicrosCodec struct{}
+type timestampMicrosCodec struct {
+	local bool
+}
 
 func (c *timestampMicrosCodec) Decode(ptr unsafe.Pointer, r *Reader) {
 	i := r.ReadLong()
 	sec := i / 1e6
 	nsec := (i - sec*1e6) * 1e3
-	*((*time.Time)(ptr)) = time.Unix(sec, nsec).UTC()
+	*((*time.Time)(ptr)) = fromTimestamp(time.Unix(sec, nsec), c.local)
 }
 
 func (c *timestampMicrosCodec) Encode(ptr unsafe.Pointer, w *Writer) {
-	t := *((*time.Time)(ptr))
+	t := toTimestamp(*((*time.Time)(ptr)), c.local)
 	w.WriteLong(t.Unix()*1e6 + int64(t.Nanosecond()/1e3))
 }
 
+type timestampNanosCodec struct {
+	local bool
+}
+
+func (c *timestampNanosCodec) Decode(ptr unsafe.Pointer, r *Reader) {
+	*((*time.Time)(ptr)) = fromTimestamp(time.Unix(0, r.ReadLong()), c.local)
+}
+
+func (c *timestampNanosCodec) Encode(ptr unsafe.Pointer, w *Writer) {
+	t := toTimestamp(*((*time.Time)(ptr)), c.local)
+	if t.Before(minTimestampNanos) || t.After(maxTimestampNanos) {
+		w.Error = fmt.Errorf("avro: time %s is out of the range of timestamp-nanos", t)
+		return
+	}
+	w.WriteLong(t.UnixNano())
+}
+
+// minTimestampNanos and maxTimestampNanos bound the times whose nanoseconds since the epoch fit in a long.
+var (
+	minTimestampNanos = time.Unix(0, math.MinInt64)
+	maxTimestampNanos = time.Unix(0, math.MaxInt64)
+)
+
+// toTimestamp returns the time to encode, the wall clock of the local time zone in UTC for local timestamps.
+func toTimestamp(t time.Time, local bool) time.Time {
+	if !local {
+		return t
+	}
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// fromTimestamp returns the decoded time in UTC, or the wall clock in the local time zone for local timestamps.
+func fromTimestamp(t time.Time, local bool) time.Time {
+	t = t.UTC()
+	if !local {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local)
+}
+
 type timeMillisCodec struct{}
 
 func (c *timeMillisCodec) Decode(ptr unsafe.Pointer, r *Reader) {
@@ -494,27 +610,7 @@ func ratFromBytes(b []byte, scale int) *big.Rat {
 }
 
 func (c *bytesDecimalCodec) Encode(ptr unsafe.Pointer, w *Writer) {
-	r := (*big.Rat)(ptr)
-	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(c.scale)), nil)
-	i := (&big.Int{}).Mul(r.Num(), scale)
-	i = i.Div(i, r.Denom())
-
-	var b []byte
-	switch i.Sign() {
-	case 0:
-		b = []byte{0}
-
-	case 1:
-		b = i.Bytes()
-		if b[0]&0x80 > 0 {
-			b = append([]byte{0}, b...)
-		}
-
-	case -1:
-		length := uint(i.BitLen()/8+1) * 8
-		b = i.Add(i, (&big.Int{}).Lsh(one, length)).Bytes()
-	}
-	w.WriteBytes(b)
+	writeDecimal(w, (*big.Rat)(ptr), c.prec, c.scale, 0)
 }
 
 type bytesDecimalPtrCodec struct {
@@ -532,24 +628,9 @@ func (c *bytesDecimalPtrCodec) Decode(ptr unsafe.Pointer, r *Reader) {
 
 func (c *bytesDecimalPtrCodec) Encode(ptr unsafe.Pointer, w *Writer) {
 	r := *((**big.Rat)(ptr))
-	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(c.scale)), nil)
-	i := (&big.Int{}).Mul(r.Num(), scale)
-	i = i.Div(i, r.Denom())
-
-	var b []byte
-	switch i.Sign() {
-	case 0:
-		b = []byte{0}
-
-	case 1:
-		b = i.Bytes()
-		if b[0]&0x80 > 0 {
-			b = append([]byte{0}, b...)
-		}
-
-	case -1:
-		length := uint(i.BitLen()/8+1) * 8
-		b = i.Add(i, (&big.Int{}).Lsh(one, length)).Bytes()
+	if r == nil {
+		w.Error = errNilDecimal
+		return
 	}
-	w.WriteBytes(b)
+	writeDecimal(w, r, c.prec, c.scale, 0)
 }
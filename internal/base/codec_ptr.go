@@ -64,3 +64,11 @@ type referenceDecoder struct {
 func (decoder *referenceDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 	decoder.decoder.Decode(unsafe.Pointer(&ptr), r)
 }
+
+type referenceEncoder struct {
+	encoder ValEncoder
+}
+
+func (encoder *referenceEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
+	encoder.encoder.Encode(unsafe.Pointer(&ptr), w)
+}
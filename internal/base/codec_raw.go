@@ -1,6 +1,8 @@
 package base
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"github.com/modern-go/reflect2"
 	"reflect"
@@ -12,44 +14,69 @@ var (
 	unmarshalerType = reflect2.TypeOfPtr((*Unmarshaler)(nil)).Elem()
 )
 
-var (
-	rawSchema = NewPrimitiveSchema(Bytes, nil)
-)
-
+// Marshaler is implemented by types encoding themselves.
+//
+// The bytes are the content of a bytes schema, or the Avro encoding of any other schema.
 type Marshaler interface {
 	MarshalAvro() ([]byte, error)
 }
 
+// Unmarshaler is implemented by types decoding themselves, see Marshaler for the bytes.
 type Unmarshaler interface {
 	UnmarshalAvro(p []byte) error
 }
 
-func createDecoderOfRaw(_ *frozenConfig, schema Schema, typ reflect2.Type) ValDecoder {
-	if typ.Implements(unmarshalerType) && typ.Kind() == reflect.Ptr && schema.Type() == Raw {
+// createDecoderOfRaw returns the decoder of Unmarshaler types, or nil if the type is not one.
+func createDecoderOfRaw(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValDecoder {
+	if typ.Implements(unmarshalerType) && typ.Kind() == reflect.Ptr {
 		return &rawCodec{
-			typ: typ,
+			typ:    typ,
+			schema: schema,
+			framed: isFramedRaw(cfg, schema),
 		}
 	}
 	ptrType := reflect2.PtrTo(typ)
-	if ptrType.Implements(unmarshalerType) && ptrType.Kind() == reflect.Ptr && schema.Type() == Raw {
+	if ptrType.Implements(unmarshalerType) && ptrType.Kind() == reflect.Ptr {
 		return &referenceDecoder{
-			&rawCodec{ptrType},
+			&rawCodec{typ: ptrType, schema: schema, framed: isFramedRaw(cfg, schema)},
 		}
 	}
-	return &errorDecoder{err: fmt.Errorf("avro: schema type %s is unsupported", schema.Type())}
+	if schema.Type() == Raw {
+		return &errorDecoder{err: fmt.Errorf("avro: %s is unsupported for Avro %s", typ.String(), schema.Type())}
+	}
+	return nil
 }
 
-func createEncoderOfRaw(_ *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
-	if typ.Implements(marshalerType) && schema.Type() == Raw {
+// createEncoderOfRaw returns the encoder of Marshaler types, or nil if the type is not one.
+func createEncoderOfRaw(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
+	if typ.Implements(marshalerType) {
 		return &rawCodec{
-			typ: typ,
+			typ:    typ,
+			schema: schema,
+			framed: isFramedRaw(cfg, schema),
 		}
 	}
-	return &errorEncoder{err: fmt.Errorf("avro: schema type %s is unsupported", schema.Type())}
+	if schema.Type() == Raw {
+		return &errorEncoder{err: fmt.Errorf("avro: %s is unsupported for Avro %s", typ.String(), schema.Type())}
+	}
+	return nil
 }
 
 type rawCodec struct {
-	typ reflect2.Type
+	typ    reflect2.Type
+	schema Schema
+	framed bool
+}
+
+// isFramedRaw determines if the bytes of marshalers are wrapped in a second bytes, as in former versions,
+// which is always the case of the raw type, and the case of bytes if the config has LegacyRawFraming.
+func isFramedRaw(cfg *frozenConfig, schema Schema) bool {
+	return schema.Type() == Raw || (schema.Type() == Bytes && cfg.config.LegacyRawFraming)
+}
+
+// isBytes determines if the bytes of the marshaler are the content of a bytes schema.
+func (c rawCodec) isBytes() bool {
+	return c.schema.Type() == Bytes || c.schema.Type() == Raw
 }
 
 func (c rawCodec) Decode(ptr unsafe.Pointer, r *Reader) {
@@ -61,12 +88,31 @@ func (c rawCodec) Decode(ptr unsafe.Pointer, r *Reader) {
 		obj = c.typ.UnsafeIndirect(ptr)
 	}
 	unmarshaler := (obj).(Unmarshaler)
-	b := r.ReadBytes()
-	p := make([]byte, 0)
-	decodeErr := Unmarshal(rawSchema, b, &p)
-	if decodeErr != nil {
-		r.ReportError("MarshalerCodec", decodeErr.Error())
-		return
+
+	var p []byte
+	if c.isBytes() {
+		p = r.ReadBytes()
+		// Nil marshalers are empty bytes, even when framed
+		if c.framed && r.Error == nil && len(p) > 0 {
+			inner := (&Reader{cfg: r.cfg}).Reset(p)
+			p = inner.ReadBytes()
+			if inner.Error != nil {
+				r.ReportError("MarshalerCodec", inner.Error.Error())
+				return
+			}
+		}
+	} else {
+		// Encode the value again, as the reader may not hold all of its bytes
+		v := r.ReadNext(c.schema)
+		if r.Error != nil {
+			return
+		}
+		var err error
+		p, err = r.cfg.MarshalGeneric(c.schema, v)
+		if err != nil {
+			r.ReportError("MarshalerCodec", err.Error())
+			return
+		}
 	}
 	err := unmarshaler.UnmarshalAvro(p)
 	if err != nil {
@@ -77,6 +123,10 @@ func (c rawCodec) Decode(ptr unsafe.Pointer, r *Reader) {
 func (c rawCodec) Encode(ptr unsafe.Pointer, w *Writer) {
 	obj := c.typ.UnsafeIndirect(ptr)
 	if c.typ.IsNullable() && reflect2.IsNil(obj) {
+		if !c.isBytes() {
+			w.Error = errors.New("avro: cannot encode nil Marshaler")
+			return
+		}
 		w.WriteBytes(nil)
 		return
 	}
@@ -86,10 +136,13 @@ func (c rawCodec) Encode(ptr unsafe.Pointer, w *Writer) {
 		w.Error = err
 		return
 	}
-	b, err = Marshal(rawSchema, b)
-	if err != nil {
-		w.Error = err
+	if c.isBytes() {
+		if c.framed {
+			framed := make([]byte, 0, len(b)+binary.MaxVarintLen64)
+			b = append(binary.AppendVarint(framed, int64(len(b))), b...)
+		}
+		w.WriteBytes(b)
 		return
 	}
-	w.WriteBytes(b)
+	_, _ = w.Write(b)
 }
@@ -88,9 +88,16 @@ func decoderOfStruct(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValDec
 			continue
 		}
 
+		fieldType := sf.Field[len(sf.Field)-1].Type()
+		var decoder ValDecoder
+		if sf.omitEmpty && isOmitEmptyUnion(field.Type(), fieldType) {
+			decoder = decoderOfOmitEmptyUnion(cfg, field.Type(), fieldType)
+		} else {
+			decoder = decoderOfType(cfg, field.Type(), fieldType)
+		}
 		fields = append(fields, &structFieldDecoder{
 			field:   sf.Field,
-			decoder: decoderOfType(cfg, field.Type(), sf.Field[len(sf.Field)-1].Type()),
+			decoder: decoder,
 		})
 	}
 
@@ -159,9 +166,16 @@ func encoderOfStruct(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEnc
 	for _, field := range rec.Fields() {
 		sf := structDesc.Fields.Get(field.Name())
 		if sf != nil {
+			fieldType := sf.Field[len(sf.Field)-1].Type()
+			var encoder ValEncoder
+			if sf.omitEmpty && isOmitEmptyUnion(field.Type(), fieldType) {
+				encoder = encoderOfOmitEmptyUnion(cfg, field.Type(), fieldType)
+			} else {
+				encoder = encoderOfType(cfg, field.Type(), fieldType)
+			}
 			fields = append(fields, &structFieldEncoder{
 				field:   sf.Field,
-				encoder: encoderOfType(cfg, field.Type(), sf.Field[len(sf.Field)-1].Type()),
+				encoder: encoder,
 			})
 			continue
 		}
@@ -395,6 +409,23 @@ func (d *recordIfaceDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 	r.ReadVal(d.schema, obj)
 }
 
+// isOmitEmptyUnion determines if values of the type of an omitempty field are encoded
+// as the nullable union, null standing for empty values.
+func isOmitEmptyUnion(schema Schema, typ reflect2.Type) bool {
+	if schema.Type() != Union || !schema.(*UnionSchema).Nullable() {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return false
+	case reflect.Map:
+		// Generic maps are the union itself
+		return typ.(reflect2.MapType).Elem().Kind() != reflect.Interface
+	default:
+		return true
+	}
+}
+
 type structDescriptor struct {
 	Type   reflect2.Type
 	Fields structFields
@@ -416,6 +447,8 @@ type structField struct {
 	Name  string
 	Field []*reflect2.UnsafeStructField
 
+	omitEmpty bool
+
 	anon *reflect2.UnsafeStructType
 }
 
@@ -465,13 +498,18 @@ func describeStruct(tagKey string, typ reflect2.Type) *structDescriptor {
 				}
 
 				fieldName := field.Name()
+				omitEmpty := false
 				if tag, ok := field.Tag().Lookup(tagKey); ok {
-					fieldName = tag
+					fieldName, omitEmpty = splitTagName(tag)
+					if fieldName == "" {
+						fieldName = field.Name()
+					}
 				}
 
 				fields = append(fields, &structField{
-					Name:  fieldName,
-					Field: chain,
+					Name:      fieldName,
+					Field:     chain,
+					omitEmpty: omitEmpty,
 				})
 			}
 		}
@@ -0,0 +1,89 @@
+package base
+
+import (
+	"unsafe"
+
+	"github.com/modern-go/reflect2"
+)
+
+type registeredCodec struct {
+	schema  Schema
+	encoder ValEncoder
+	decoder ValDecoder
+}
+
+// RegisterCodec binds the encoder and decoder of a type to schema.
+// The codecs are used when the type is en/decoded with schema, and schema is the derived schema of the type.
+//
+// Register codecs before the type is first used, as codecs and derived schemas are cached.
+func (c *frozenConfig) RegisterCodec(typ reflect2.Type, schema Schema, encoder ValEncoder, decoder ValDecoder) {
+	c.codecs.Store(typ.RType(), &registeredCodec{
+		schema:  schema,
+		encoder: encoder,
+		decoder: decoder,
+	})
+}
+
+func (c *frozenConfig) codecOf(typ reflect2.Type) *registeredCodec {
+	v, ok := c.codecs.Load(typ.RType())
+	if !ok {
+		return nil
+	}
+	return v.(*registeredCodec)
+}
+
+// RegisterCodec binds the encoder and decoder of a type to schema with the default config.
+func RegisterCodec(typ reflect2.Type, schema Schema, encoder ValEncoder, decoder ValDecoder) {
+	DefaultConfig.RegisterCodec(typ, schema, encoder, decoder)
+}
+
+// RegisterCodecFunc binds the encode and decode functions of T to schema with the default config.
+func RegisterCodecFunc[T any](schema Schema, encode func(w *Writer, v T), decode func(r *Reader) T) {
+	codec := &funcCodec[T]{encode: encode, decode: decode}
+	RegisterCodec(reflect2.TypeOfPtr((*T)(nil)).Elem(), schema, codec, codec)
+}
+
+// parseRegisteredType returns the schema of types with a codec registered with the config, or nil.
+func parseRegisteredType(cfg *frozenConfig, typ reflect2.Type) Schema {
+	rc := cfg.codecOf(typ)
+	if rc == nil {
+		return nil
+	}
+	return rc.schema
+}
+
+func createDecoderOfRegistered(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValDecoder {
+	rc := cfg.codecOf(typ)
+	if rc == nil || rc.decoder == nil || !isRegisteredSchema(rc, schema) {
+		return nil
+	}
+	return rc.decoder
+}
+
+func createEncoderOfRegistered(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
+	rc := cfg.codecOf(typ)
+	if rc == nil || rc.encoder == nil || !isRegisteredSchema(rc, schema) {
+		return nil
+	}
+	return rc.encoder
+}
+
+func isRegisteredSchema(rc *registeredCodec, schema Schema) bool {
+	if ref, ok := schema.(*RefSchema); ok {
+		schema = ref.Schema()
+	}
+	return schema.Fingerprint() == rc.schema.Fingerprint()
+}
+
+type funcCodec[T any] struct {
+	encode func(w *Writer, v T)
+	decode func(r *Reader) T
+}
+
+func (c *funcCodec[T]) Decode(ptr unsafe.Pointer, r *Reader) {
+	*((*T)(ptr)) = c.decode(r)
+}
+
+func (c *funcCodec[T]) Encode(ptr unsafe.Pointer, w *Writer) {
+	c.encode(w, *((*T)(ptr)))
+}
@@ -0,0 +1,676 @@
+package base
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"unsafe"
+
+	"github.com/modern-go/reflect2"
+)
+
+// ReadValWithWriterSchema parses Avro value that was written with the writer schema, resolves it
+// against the reader schema and stores the result in the value pointed to by obj.
+func (r *Reader) ReadValWithWriterSchema(schema, writer Schema, obj any) {
+	if schema.Fingerprint() == writer.Fingerprint() {
+		r.ReadVal(schema, obj)
+		return
+	}
+
+	fingerprint := resolvedFingerprint(schema, writer)
+	decoder := r.cfg.getDecoderFromCache(fingerprint, reflect2.RTypeOf(obj))
+	if decoder == nil {
+		typ := reflect2.TypeOf(obj)
+		if typ.Kind() != reflect.Ptr {
+			r.ReportError("ReadVal", "can only unmarshal into pointer")
+			return
+		}
+		decoder = r.cfg.resolvedDecoderOf(schema, writer, typ)
+	}
+
+	ptr := reflect2.PtrOf(obj)
+	if ptr == nil {
+		r.ReportError("ReadVal", "can not read into nil pointer")
+		return
+	}
+
+	decoder.Decode(ptr, r)
+}
+
+func resolvedFingerprint(schema, writer Schema) [32]byte {
+	readerFingerprint := schema.Fingerprint()
+	writerFingerprint := writer.Fingerprint()
+	b := make([]byte, 0, 64)
+	b = append(b, readerFingerprint[:]...)
+	b = append(b, writerFingerprint[:]...)
+	return sha256.Sum256(b)
+}
+
+func (c *frozenConfig) resolvedDecoderOf(schema, writer Schema, typ reflect2.Type) ValDecoder {
+	fingerprint := resolvedFingerprint(schema, writer)
+	ptrType := typ.(*reflect2.UnsafePtrType)
+	decoder := decoderOfResolvedType(c, schema, writer, ptrType.Elem())
+	c.addDecoderToCache(fingerprint, typ.RType(), decoder)
+	return decoder
+}
+
+// decoderOfResolvedType returns a decoder that reads data written with the writer
+// schema into a value of typ, which is described by the reader schema.
+func decoderOfResolvedType(cfg *frozenConfig, schema, writer Schema, typ reflect2.Type) ValDecoder {
+	if schema.Type() == Ref {
+		schema = schema.(*RefSchema).Schema()
+	}
+	if writer.Type() == Ref {
+		writer = writer.(*RefSchema).Schema()
+	}
+
+	if schema.Fingerprint() == writer.Fingerprint() {
+		return decoderOfType(cfg, schema, typ)
+	}
+
+	if writer.Type() == Union {
+		return decoderOfResolvedWriterUnion(cfg, schema, writer, typ)
+	}
+	if schema.Type() == Union {
+		return decoderOfResolvedReaderUnion(cfg, schema, writer, typ)
+	}
+
+	if typ.Kind() == reflect.Interface {
+		return decoderOfResolvedEface(cfg, schema, writer, typ)
+	}
+
+	if schema.Type() != writer.Type() {
+		return decoderOfPromotion(cfg, schema, writer, typ)
+	}
+
+	switch schema.Type() {
+	case Record:
+		return decoderOfResolvedRecord(cfg, schema, writer, typ)
+
+	case Enum:
+		return decoderOfResolvedEnum(schema, writer, typ)
+
+	case Array:
+		return decoderOfResolvedArray(cfg, schema, writer, typ)
+
+	case Map:
+		return decoderOfResolvedMap(cfg, schema, writer, typ)
+
+	case Fixed:
+		r := schema.(*FixedSchema)
+		w := writer.(*FixedSchema)
+		if !namesMatch(r, w) || r.Size() != w.Size() {
+			return &errorDecoder{err: fmt.Errorf("avro: reader fixed %s is not compatible with writer fixed %s", r.FullName(), w.FullName())}
+		}
+	}
+
+	// The encodings are the same, prefer the writer logical type when typ supports it.
+	if dec := decoderOfType(cfg, writer, typ); !isErrorDecoder(dec) {
+		return dec
+	}
+	return decoderOfType(cfg, schema, typ)
+}
+
+func decoderOfResolvedEface(cfg *frozenConfig, schema, writer Schema, typ reflect2.Type) ValDecoder {
+	var elemType reflect2.Type
+	switch {
+	case schema.Type() == Enum && writer.Type() == Enum:
+		elemType = reflect2.TypeOf("")
+	case schema.Type() == Long && writer.Type() == Int:
+		elemType = reflect2.TypeOf(int64(0))
+	case schema.Type() == Float && isPromotable(writer.Type(), Float):
+		elemType = reflect2.TypeOf(float32(0))
+	case schema.Type() == Double && isPromotable(writer.Type(), Double):
+		elemType = reflect2.TypeOf(float64(0))
+	default:
+		// There is no reader type to resolve into, read the writer data as is.
+		return decoderOfType(cfg, writer, typ)
+	}
+
+	return &resolvedEfaceDecoder{
+		typ:     typ,
+		elemTyp: elemType,
+		decoder: decoderOfResolvedType(cfg, schema, writer, elemType),
+	}
+}
+
+// resolvedEfaceDecoder decodes into the Go type of the reader schema and stores it in an interface.
+type resolvedEfaceDecoder struct {
+	typ     reflect2.Type
+	elemTyp reflect2.Type
+	decoder ValDecoder
+}
+
+func (d *resolvedEfaceDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
+	elem := d.elemTyp.UnsafeNew()
+	d.decoder.Decode(elem, r)
+	obj := d.elemTyp.UnsafeIndirect(elem)
+	d.typ.UnsafeSet(ptr, unsafe.Pointer(&obj))
+}
+
+func isErrorDecoder(dec ValDecoder) bool {
+	_, ok := dec.(*errorDecoder)
+	return ok
+}
+
+func decoderOfPromotion(cfg *frozenConfig, schema, writer Schema, typ reflect2.Type) ValDecoder {
+	switch {
+	case writer.Type() == Int && schema.Type() == Long,
+		writer.Type() == String && schema.Type() == Bytes,
+		writer.Type() == Bytes && schema.Type() == String:
+		// The binary encodings are the same.
+		return decoderOfType(cfg, schema, typ)
+
+	case isPromotable(writer.Type(), schema.Type()):
+		switch typ.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return &floatPromotionDecoder{from: writer.Type(), typ: typ}
+		}
+	}
+
+	return &errorDecoder{
+		err: fmt.Errorf("avro: reader schema %s not compatible with writer schema %s", schema.Type(), writer.Type()),
+	}
+}
+
+func isPromotable(writer, reader Type) bool {
+	switch writer {
+	case Int:
+		return reader == Long || reader == Float || reader == Double
+	case Long:
+		return reader == Float || reader == Double
+	case Float:
+		return reader == Double
+	case String:
+		return reader == Bytes
+	case Bytes:
+		return reader == String
+	}
+	return false
+}
+
+type floatPromotionDecoder struct {
+	from Type
+	typ  reflect2.Type
+}
+
+func (d *floatPromotionDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
+	var f float64
+	switch d.from {
+	case Int:
+		f = float64(r.ReadInt())
+	case Long:
+		f = float64(r.ReadLong())
+	case Float:
+		f = float64(r.ReadFloat())
+	}
+
+	if d.typ.Kind() == reflect.Float32 {
+		*((*float32)(ptr)) = float32(f)
+		return
+	}
+	*((*float64)(ptr)) = f
+}
+
+func decoderOfResolvedRecord(cfg *frozenConfig, schema, writer Schema, typ reflect2.Type) ValDecoder {
+	rec := schema.(*RecordSchema)
+	wrec := writer.(*RecordSchema)
+	if !namesMatch(rec, wrec) {
+		return &errorDecoder{err: fmt.Errorf("avro: reader record %s is not compatible with writer record %s", rec.FullName(), wrec.FullName())}
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		return decoderOfResolvedStruct(cfg, rec, wrec, typ)
+
+	case reflect.Map:
+		if typ.(reflect2.MapType).Key().Kind() != reflect.String ||
+			typ.(reflect2.MapType).Elem().Kind() != reflect.Interface {
+			break
+		}
+		return decoderOfResolvedRecordMap(cfg, rec, wrec, typ)
+
+	case reflect.Ptr:
+		elemType := typ.(*reflect2.UnsafePtrType).Elem()
+		return &dereferenceDecoder{typ: elemType, decoder: decoderOfResolvedType(cfg, schema, writer, elemType)}
+	}
+
+	return &errorDecoder{err: fmt.Errorf("avro: %s is unsupported for avro %s", typ.String(), schema.Type())}
+}
+
+func decoderOfResolvedStruct(cfg *frozenConfig, rec, wrec *RecordSchema, typ reflect2.Type) ValDecoder {
+	fingerprint := resolvedFingerprint(rec, wrec)
+	processing := cfg.getProcessingDecoderFromCache(fingerprint, typ.RType())
+	if processing != nil {
+		return processing
+	}
+	dec := &structDecoder{typ: typ, fields: nil}
+	cfg.addProcessingDecoderToCache(fingerprint, typ.RType(), dec)
+
+	structDesc := describeStruct(cfg.getTagKey(), typ)
+
+	fields := make([]*structFieldDecoder, 0, len(rec.Fields()))
+	resolved := make(map[string]bool, len(rec.Fields()))
+	for _, wf := range wrec.Fields() {
+		field := resolveField(rec, wf)
+		if field == nil {
+			// The field was removed from the reader schema
+			fields = append(fields, &structFieldDecoder{
+				decoder: createSkipDecoder(wf.Type()),
+			})
+			continue
+		}
+		resolved[field.Name()] = true
+
+		sf := getStructField(structDesc, field)
+		if sf == nil {
+			fields = append(fields, &structFieldDecoder{
+				decoder: createSkipDecoder(wf.Type()),
+			})
+			continue
+		}
+
+		fields = append(fields, &structFieldDecoder{
+			field:   sf.Field,
+			decoder: decoderOfResolvedField(cfg, field, wf.Type(), sf.Field[len(sf.Field)-1].Type()),
+		})
+	}
+
+	for _, field := range rec.Fields() {
+		if resolved[field.Name()] {
+			continue
+		}
+		if !field.HasDefault() {
+			err := fmt.Errorf("avro: reader field %s is missing in writer schema and has no default", field.Name())
+			dec.fields = []*structFieldDecoder{{decoder: &errorDecoder{err: err}}}
+			return dec
+		}
+
+		sf := getStructField(structDesc, field)
+		if sf == nil {
+			continue
+		}
+
+		fields = append(fields, &structFieldDecoder{
+			field:   sf.Field,
+			decoder: decoderOfDefault(cfg, field, sf.Field[len(sf.Field)-1].Type()),
+		})
+	}
+
+	dec.fields = fields
+	return dec
+}
+
+func getStructField(structDesc *structDescriptor, field *Field) *structField {
+	sf := structDesc.Fields.Get(field.Name())
+	if sf != nil {
+		return sf
+	}
+	for _, alias := range field.Aliases() {
+		sf = structDesc.Fields.Get(alias)
+		if sf != nil {
+			return sf
+		}
+	}
+	return nil
+}
+
+// resolveField finds the reader field matching the writer field by name or alias.
+func resolveField(rec *RecordSchema, wf *Field) *Field {
+	for _, field := range rec.Fields() {
+		if field.Name() == wf.Name() {
+			return field
+		}
+	}
+	for _, field := range rec.Fields() {
+		for _, alias := range field.Aliases() {
+			if alias == wf.Name() {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+func decoderOfResolvedRecordMap(cfg *frozenConfig, rec, wrec *RecordSchema, typ reflect2.Type) ValDecoder {
+	mapType := typ.(*reflect2.UnsafeMapType)
+
+	fields := make([]resolvedRecordMapField, 0, len(wrec.Fields()))
+	resolved := make(map[string]bool, len(rec.Fields()))
+	for _, wf := range wrec.Fields() {
+		field := resolveField(rec, wf)
+		if field == nil {
+			fields = append(fields, resolvedRecordMapField{decoder: createSkipDecoder(wf.Type())})
+			continue
+		}
+		resolved[field.Name()] = true
+
+		fields = append(fields, resolvedRecordMapField{
+			name:    field.Name(),
+			decoder: decoderOfResolvedField(cfg, field, wf.Type(), mapType.Elem()),
+		})
+	}
+
+	for _, field := range rec.Fields() {
+		if resolved[field.Name()] {
+			continue
+		}
+		if !field.HasDefault() {
+			err := fmt.Errorf("avro: reader field %s is missing in writer schema and has no default", field.Name())
+			return &errorDecoder{err: err}
+		}
+		// Defaults are decoded into the generic values of the reader schema, not kept in their JSON form
+		fields = append(fields, resolvedRecordMapField{
+			name:    field.Name(),
+			decoder: decoderOfDefault(cfg, field, mapType.Elem()),
+		})
+	}
+
+	return &resolvedRecordMapDecoder{
+		mapType:  mapType,
+		elemType: mapType.Elem(),
+		fields:   fields,
+	}
+}
+
+// decoderOfResolvedField returns a decoder that reads the writer field into a value of typ,
+// which is described by the reader field.
+//
+// Null writer values of fields that are not nullable in the reader decode the reader default, if there is one.
+func decoderOfResolvedField(cfg *frozenConfig, field *Field, writer Schema, typ reflect2.Type) ValDecoder {
+	wunion, isUnion := writer.(*UnionSchema)
+	if !isUnion || !field.HasDefault() || field.Type().Type() == Union || typ.Kind() == reflect.Ptr {
+		return decoderOfResolvedType(cfg, field.Type(), writer, typ)
+	}
+	if _, idx := wunion.Types().Get(string(Null)); idx < 0 {
+		return decoderOfResolvedType(cfg, field.Type(), writer, typ)
+	}
+
+	decoders := make([]ValDecoder, len(wunion.Types()))
+	for i, wt := range wunion.Types() {
+		if wt.Type() == Null {
+			decoders[i] = decoderOfDefault(cfg, field, typ)
+			continue
+		}
+		decoders[i] = decoderOfResolvedUnionBranch(cfg, field.Type(), wt, typ)
+	}
+	return &resolvedUnionDecoder{schema: wunion, decoders: decoders}
+}
+
+type resolvedRecordMapField struct {
+	name    string
+	decoder ValDecoder
+}
+
+type resolvedRecordMapDecoder struct {
+	mapType  *reflect2.UnsafeMapType
+	elemType reflect2.Type
+	fields   []resolvedRecordMapField
+}
+
+func (d *resolvedRecordMapDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
+	if d.mapType.UnsafeIsNil(ptr) {
+		d.mapType.UnsafeSet(ptr, d.mapType.UnsafeMakeMap(len(d.fields)))
+	}
+
+	for _, field := range d.fields {
+		// Skip case
+		if field.name == "" {
+			field.decoder.Decode(nil, r)
+			continue
+		}
+
+		elem := d.elemType.UnsafeNew()
+		field.decoder.Decode(elem, r)
+
+		name := field.name
+		d.mapType.UnsafeSetIndex(ptr, reflect2.PtrOf(&name), elem)
+	}
+
+	if r.Error != nil && !errors.Is(r.Error, io.EOF) {
+		r.Error = fmt.Errorf("%v: %w", d.mapType, r.Error)
+	}
+}
+
+func decoderOfResolvedEnum(schema, writer Schema, typ reflect2.Type) ValDecoder {
+	enum := schema.(*EnumSchema)
+	wenum := writer.(*EnumSchema)
+	if !namesMatch(enum, wenum) {
+		return &errorDecoder{err: fmt.Errorf("avro: reader enum %s is not compatible with writer enum %s", enum.FullName(), wenum.FullName())}
+	}
+
+	// Map the writer symbol positions to reader symbols
+	symbols := make([]string, len(wenum.Symbols()))
+	for i, sym := range wenum.Symbols() {
+		switch {
+		case hasSymbol(enum.Symbols(), sym):
+			symbols[i] = sym
+		case enum.Default() != "":
+			symbols[i] = enum.Default()
+		}
+	}
+
+	switch {
+	case typ.Kind() == reflect.String:
+	case reflect2.PtrTo(typ).Implements(textUnmarshalerType):
+	case isEnumIndexKind(typ.Kind()):
+	default:
+		return &errorDecoder{err: fmt.Errorf("avro: %s is unsupported for Avro %s", typ.String(), schema.Type())}
+	}
+
+	return &resolvedEnumDecoder{typ: typ, symbols: symbols, writerSymbols: wenum.Symbols(), readerSymbols: enum.Symbols()}
+}
+
+type resolvedEnumDecoder struct {
+	typ           reflect2.Type
+	symbols       []string
+	writerSymbols []string
+	readerSymbols []string
+}
+
+func (d *resolvedEnumDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
+	i := int(r.ReadInt())
+	if i < 0 || i >= len(d.symbols) {
+		r.ReportError("decode enum symbol", "unknown enum symbol")
+		return
+	}
+	sym := d.symbols[i]
+	if sym == "" {
+		r.ReportError("decode enum symbol", fmt.Sprintf("reader is missing symbol %s", d.writerSymbols[i]))
+		return
+	}
+
+	val := reflect.NewAt(d.typ.Type1(), ptr)
+	if d.typ.Kind() == reflect.String {
+		val.Elem().SetString(sym)
+		return
+	}
+	if !val.Type().Implements(textUnmarshalerType.Type1()) {
+		// Integer enums are the index of the symbol in the reader
+		for j, readerSym := range d.readerSymbols {
+			if readerSym == sym {
+				setEnumIndex(d.typ, ptr, j)
+				return
+			}
+		}
+		return
+	}
+	if err := val.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(sym)); err != nil {
+		r.ReportError("decode enum text unmarshaler", err.Error())
+	}
+}
+
+func decoderOfResolvedArray(cfg *frozenConfig, schema, writer Schema, typ reflect2.Type) ValDecoder {
+	if typ.Kind() != reflect.Slice {
+		return &errorDecoder{err: fmt.Errorf("avro: %s is unsupported for Avro %s", typ.String(), schema.Type())}
+	}
+
+	sliceType := typ.(*reflect2.UnsafeSliceType)
+	decoder := decoderOfResolvedType(cfg, schema.(*ArraySchema).Items(), writer.(*ArraySchema).Items(), sliceType.Elem())
+
+	return &arrayDecoder{typ: sliceType, decoder: decoder}
+}
+
+func decoderOfResolvedMap(cfg *frozenConfig, schema, writer Schema, typ reflect2.Type) ValDecoder {
+	if typ.Kind() != reflect.Map {
+		return &errorDecoder{err: fmt.Errorf("avro: %s is unsupported for Avro %s", typ.String(), schema.Type())}
+	}
+
+	mapType := typ.(*reflect2.UnsafeMapType)
+	decoder := decoderOfResolvedType(cfg, schema.(*MapSchema).Values(), writer.(*MapSchema).Values(), mapType.Elem())
+
+	switch {
+	case mapType.Key().Kind() == reflect.String:
+		return &mapDecoder{
+			mapType:  mapType,
+			elemType: mapType.Elem(),
+			decoder:  decoder,
+		}
+	case mapType.Key().Implements(textUnmarshalerType):
+		return &mapDecoderUnmarshaler{
+			mapType:  mapType,
+			keyType:  mapType.Key(),
+			elemType: mapType.Elem(),
+			decoder:  decoder,
+		}
+	}
+
+	return &errorDecoder{err: fmt.Errorf("avro: %s is unsupported for Avro %s", typ.String(), schema.Type())}
+}
+
+func decoderOfResolvedWriterUnion(cfg *frozenConfig, schema, writer Schema, typ reflect2.Type) ValDecoder {
+	wunion := writer.(*UnionSchema)
+
+	switch typ.Kind() {
+	case reflect.Interface, reflect.Map:
+		if schema.Type() == Union {
+			// Generic targets keep the writer union branches.
+			return decoderOfType(cfg, writer, typ)
+		}
+	}
+
+	decoders := make([]ValDecoder, len(wunion.Types()))
+	for i, wt := range wunion.Types() {
+		decoders[i] = decoderOfResolvedUnionBranch(cfg, schema, wt, typ)
+	}
+
+	return &resolvedUnionDecoder{schema: wunion, decoders: decoders}
+}
+
+func decoderOfResolvedUnionBranch(cfg *frozenConfig, schema, writer Schema, typ reflect2.Type) ValDecoder {
+	if writer.Type() == Null {
+		if typ.Kind() == reflect.Ptr {
+			return &nullPtrDecoder{}
+		}
+		if schema.Type() == Union {
+			if _, idx := schema.(*UnionSchema).Types().Get(string(Null)); idx >= 0 {
+				return &zeroDecoder{typ: typ}
+			}
+		}
+		return &errorDecoder{err: fmt.Errorf("avro: reader schema %s not compatible with writer schema null", schema.Type())}
+	}
+
+	target := schema
+	if schema.Type() == Union {
+		target = resolveUnionBranch(schema.(*UnionSchema), writer)
+		if target == nil {
+			return &errorDecoder{err: fmt.Errorf("avro: reader union lacking writer schema %s", schemaTypeName(writer))}
+		}
+	}
+
+	if typ.Kind() == reflect.Ptr && schema.Type() == Union {
+		elemType := typ.(*reflect2.UnsafePtrType).Elem()
+		return &dereferenceDecoder{typ: elemType, decoder: decoderOfResolvedType(cfg, target, writer, elemType)}
+	}
+	return decoderOfResolvedType(cfg, target, writer, typ)
+}
+
+func decoderOfResolvedReaderUnion(cfg *frozenConfig, schema, writer Schema, typ reflect2.Type) ValDecoder {
+	switch typ.Kind() {
+	case reflect.Interface, reflect.Map:
+		// Generic targets keep the writer schema.
+		return decoderOfType(cfg, writer, typ)
+	}
+
+	return decoderOfResolvedUnionBranch(cfg, schema, writer, typ)
+}
+
+// resolveUnionBranch finds the first reader union branch that matches the writer schema.
+func resolveUnionBranch(union *UnionSchema, writer Schema) Schema {
+	name := schemaTypeName(writer)
+	if schema, _ := union.Types().Get(name); schema != nil {
+		return schema
+	}
+
+	for _, schema := range union.Types() {
+		if schema.Type() == Ref {
+			schema = schema.(*RefSchema).Schema()
+		}
+		if schema.Type() != writer.Type() {
+			continue
+		}
+		named, isNamed := schema.(NamedSchema)
+		if !isNamed {
+			return schema
+		}
+		if namesMatch(named, writer.(NamedSchema)) {
+			return schema
+		}
+	}
+
+	for _, schema := range union.Types() {
+		if isPromotable(writer.Type(), schema.Type()) {
+			return schema
+		}
+	}
+
+	return nil
+}
+
+type resolvedUnionDecoder struct {
+	schema   *UnionSchema
+	decoders []ValDecoder
+}
+
+func (d *resolvedUnionDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
+	i, schema := getUnionSchema(d.schema, r)
+	if schema == nil {
+		return
+	}
+
+	d.decoders[i].Decode(ptr, r)
+}
+
+// zeroDecoder decodes null into the zero value of non-pointer types.
+type zeroDecoder struct {
+	typ reflect2.Type
+}
+
+func (d *zeroDecoder) Decode(ptr unsafe.Pointer, _ *Reader) {
+	d.typ.UnsafeSet(ptr, d.typ.UnsafeNew())
+}
+
+type nullPtrDecoder struct{}
+
+func (*nullPtrDecoder) Decode(ptr unsafe.Pointer, _ *Reader) {
+	*((*unsafe.Pointer)(ptr)) = nil
+}
+
+// namesMatch determines if named reader and writer schemas match, either by their
+// unqualified names or by the reader aliases.
+func namesMatch(reader, writer NamedSchema) bool {
+	if reader.FullName() == writer.FullName() || reader.Name() == writer.Name() {
+		return true
+	}
+	if aliased, ok := reader.(interface{ Aliases() []string }); ok {
+		for _, alias := range aliased.Aliases() {
+			if alias == writer.FullName() {
+				return true
+			}
+		}
+	}
+	return false
+}
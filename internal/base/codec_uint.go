@@ -0,0 +1,132 @@
+package base
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"unsafe"
+
+	"github.com/modern-go/reflect2"
+)
+
+// UintStrategy determines the Avro type uint and uint64 values are mapped to, as Avro has no unsigned integers.
+type UintStrategy int
+
+const (
+	// UintFixed maps unsigned integers to the big-endian fixed(8) go.uint, with the go.uint64 property.
+	UintFixed UintStrategy = iota
+	// UintLong maps unsigned integers to long, failing to encode values over math.MaxInt64.
+	UintLong
+	// UintDecimal maps unsigned integers to bytes of decimal(20,0).
+	UintDecimal
+)
+
+// UintProp is the schema property marking fixed(8) schemas of unsigned integers.
+const UintProp = "go.uint64"
+
+// parseUintType returns the schema of unsigned integers of the strategy of the config.
+func parseUintType(cfg *frozenConfig) (Schema, error) {
+	switch cfg.config.UintStrategy {
+	case UintLong:
+		return NewPrimitiveSchema(Long, nil), nil
+	case UintDecimal:
+		return NewPrimitiveSchema(Bytes, NewDecimalLogicalSchema(20, 0)), nil
+	default:
+		// The fixed is in the go namespace, which no derived type has, so that it does not collide with
+		// other types named uint. It keeps the name of former versions, so that their writer schemas still resolve.
+		return NewFixedSchema("uint", "go", 8, nil, WithProps(map[string]any{UintProp: true}))
+	}
+}
+
+type largeUint interface {
+	~uint | ~uint64
+}
+
+type uintCodec interface {
+	ValDecoder
+	ValEncoder
+}
+
+// createCodecOfUint returns the codec of uint and uint64 for long, fixed(8) and decimal schemas of scale 0,
+// or nil if the schema is none of them.
+func createCodecOfUint(schema Schema, typ reflect2.Type) uintCodec {
+	if typ.Kind() == reflect.Uint {
+		return createCodecOfUintOf[uint](schema)
+	}
+	return createCodecOfUintOf[uint64](schema)
+}
+
+func createCodecOfUintOf[T largeUint](schema Schema) uintCodec {
+	switch schema.Type() {
+	case Long:
+		return &uintLongCodec[T]{}
+	case Fixed:
+		if schema.(*FixedSchema).Size() == 8 {
+			return &fixedUintCodec[T]{}
+		}
+	case Bytes:
+		if dec, ok := getLogicalSchema(schema).(*DecimalLogicalSchema); ok && dec.Scale() == 0 {
+			return &uintDecimalCodec[T]{}
+		}
+	}
+	return nil
+}
+
+type uintLongCodec[T largeUint] struct{}
+
+func (*uintLongCodec[T]) Decode(ptr unsafe.Pointer, r *Reader) {
+	i := r.ReadLong()
+	if i < 0 {
+		r.ReportError("uintLongCodec", fmt.Sprintf("%d overflows unsigned integer", i))
+		return
+	}
+	*((*T)(ptr)) = T(i)
+}
+
+func (*uintLongCodec[T]) Encode(ptr unsafe.Pointer, w *Writer) {
+	u := uint64(*((*T)(ptr)))
+	if u > math.MaxInt64 {
+		w.Error = fmt.Errorf("avro: %d overflows long", u)
+		return
+	}
+	w.WriteLong(int64(u))
+}
+
+type fixedUintCodec[T largeUint] struct{}
+
+func (*fixedUintCodec[T]) Decode(ptr unsafe.Pointer, r *Reader) {
+	var buffer [8]byte
+	r.Read(buffer[:])
+	*((*T)(ptr)) = T(binary.BigEndian.Uint64(buffer[:]))
+}
+
+func (*fixedUintCodec[T]) Encode(ptr unsafe.Pointer, w *Writer) {
+	var buffer [8]byte
+	binary.BigEndian.PutUint64(buffer[:], uint64(*((*T)(ptr))))
+	_, _ = w.Write(buffer[:])
+}
+
+type uintDecimalCodec[T largeUint] struct{}
+
+func (*uintDecimalCodec[T]) Decode(ptr unsafe.Pointer, r *Reader) {
+	b := r.ReadBytes()
+	i := (&big.Int{}).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 > 0 {
+		i.Sub(i, new(big.Int).Lsh(one, uint(len(b))*8))
+	}
+	if i.Sign() < 0 || !i.IsUint64() {
+		r.ReportError("uintDecimalCodec", fmt.Sprintf("%s overflows unsigned integer", i.String()))
+		return
+	}
+	*((*T)(ptr)) = T(i.Uint64())
+}
+
+func (*uintDecimalCodec[T]) Encode(ptr unsafe.Pointer, w *Writer) {
+	b := new(big.Int).SetUint64(uint64(*((*T)(ptr)))).Bytes()
+	if len(b) == 0 || b[0]&0x80 > 0 {
+		b = append([]byte{0}, b...)
+	}
+	w.WriteBytes(b)
+}
@@ -26,14 +26,12 @@ func createDecoderOfUnion(cfg *frozenConfig, schema Schema, typ reflect2.Type) V
 		return decoderOfPtrUnion(cfg, schema, typ)
 
 	case reflect.Interface:
-		if _, ok := typ.(*reflect2.UnsafeIFaceType); !ok {
-			dec, err := decoderOfResolvedUnion(cfg, schema)
-			if err != nil {
-				return &errorDecoder{err: fmt.Errorf("avro: problem resolving decoder for Avro %s: %w", schema.Type(), err)}
-			}
-
-			return dec
+		dec, err := decoderOfResolvedUnion(cfg, schema, typ)
+		if err != nil {
+			return &errorDecoder{err: fmt.Errorf("avro: problem resolving decoder for Avro %s: %w", schema.Type(), err)}
 		}
+
+		return dec
 	default:
 		break
 	}
@@ -231,8 +229,91 @@ func (e *unionPtrEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
 	e.encoder.Encode(*((*unsafe.Pointer)(ptr)), w)
 }
 
-func decoderOfResolvedUnion(cfg *frozenConfig, schema Schema) (ValDecoder, error) {
+// decoderOfOmitEmptyUnion returns the decoder of a nullable union into a value of an omitempty field,
+// where null is decoded as the zero value.
+func decoderOfOmitEmptyUnion(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValDecoder {
 	union := schema.(*UnionSchema)
+	_, typeIdx := union.Indices()
+
+	return &unionOmitEmptyDecoder{
+		schema:  union,
+		typ:     typ,
+		decoder: decoderOfType(cfg, union.Types()[typeIdx], typ),
+	}
+}
+
+type unionOmitEmptyDecoder struct {
+	schema  *UnionSchema
+	typ     reflect2.Type
+	decoder ValDecoder
+}
+
+func (d *unionOmitEmptyDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
+	_, schema := getUnionSchema(d.schema, r)
+	if schema == nil {
+		return
+	}
+
+	if schema.Type() == Null {
+		d.typ.UnsafeSet(ptr, d.typ.UnsafeNew())
+		return
+	}
+
+	d.decoder.Decode(ptr, r)
+}
+
+// encoderOfOmitEmptyUnion returns the encoder of a value of an omitempty field to a nullable union,
+// where empty values are encoded as null.
+func encoderOfOmitEmptyUnion(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
+	union := schema.(*UnionSchema)
+	nullIdx, typeIdx := union.Indices()
+
+	return &unionOmitEmptyEncoder{
+		typ:     typ.Type1(),
+		encoder: encoderOfType(cfg, union.Types()[typeIdx], typ),
+		nullIdx: int64(nullIdx),
+		typeIdx: int64(typeIdx),
+	}
+}
+
+type unionOmitEmptyEncoder struct {
+	typ     reflect.Type
+	encoder ValEncoder
+	nullIdx int64
+	typeIdx int64
+}
+
+func (e *unionOmitEmptyEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
+	if isEmptyValue(reflect.NewAt(e.typ, ptr).Elem()) {
+		w.WriteLong(e.nullIdx)
+		return
+	}
+
+	w.WriteLong(e.typeIdx)
+	e.encoder.Encode(ptr, w)
+}
+
+// isEmptyValue reports whether v is empty in the sense of the omitempty option of encoding/json,
+// with the addition of zero structs.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+func decoderOfResolvedUnion(cfg *frozenConfig, schema Schema, ifaceType reflect2.Type) (ValDecoder, error) {
+	union := schema.(*UnionSchema)
+
+	// Named interfaces can only hold the types implementing them
+	var iface reflect2.Type
+	if _, ok := ifaceType.(*reflect2.UnsafeIFaceType); ok {
+		iface = ifaceType
+	}
 
 	types := make([]reflect2.Type, len(union.Types()))
 	decoders := make([]ValDecoder, len(union.Types()))
@@ -255,6 +336,12 @@ func decoderOfResolvedUnion(cfg *frozenConfig, schema Schema) (ValDecoder, error
 			types = []reflect2.Type{}
 			break
 		}
+		if iface != nil && schema.Type() != Null && !typ.Implements(iface) {
+			if typ.Kind() == reflect.Ptr || !reflect2.PtrTo(typ).Implements(iface) {
+				return nil, fmt.Errorf("avro: %s does not implement %s", typ.String(), iface.String())
+			}
+			typ = reflect2.PtrTo(typ)
+		}
 
 		decoder := decoderOfType(cfg, schema, typ)
 		decoders[i] = decoder
@@ -264,6 +351,7 @@ func decoderOfResolvedUnion(cfg *frozenConfig, schema Schema) (ValDecoder, error
 	return &unionResolvedDecoder{
 		cfg:      cfg,
 		schema:   union,
+		iface:    iface,
 		types:    types,
 		decoders: decoders,
 	}, nil
@@ -272,6 +360,7 @@ func decoderOfResolvedUnion(cfg *frozenConfig, schema Schema) (ValDecoder, error
 type unionResolvedDecoder struct {
 	cfg      *frozenConfig
 	schema   *UnionSchema
+	iface    reflect2.Type
 	types    []reflect2.Type
 	decoders []ValDecoder
 }
@@ -282,6 +371,11 @@ func (d *unionResolvedDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 		return
 	}
 
+	if d.iface != nil {
+		d.decodeIface(i, schema, ptr, r)
+		return
+	}
+
 	pObj := (*any)(ptr)
 
 	if schema.Type() == Null {
@@ -315,10 +409,6 @@ func (d *unionResolvedDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 		mapType := typ.(*reflect2.UnsafeSliceType)
 		newPtr = mapType.UnsafeMakeSlice(1, 1)
 
-	case reflect.Ptr:
-		elemType := typ.(*reflect2.UnsafePtrType).Elem()
-		newPtr = elemType.UnsafeNew()
-
 	default:
 		newPtr = typ.UnsafeNew()
 	}
@@ -327,6 +417,24 @@ func (d *unionResolvedDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 	*pObj = typ.UnsafeIndirect(newPtr)
 }
 
+func (d *unionResolvedDecoder) decodeIface(i int, schema Schema, ptr unsafe.Pointer, r *Reader) {
+	val := reflect.NewAt(d.iface.Type1(), ptr).Elem()
+	if schema.Type() == Null {
+		val.Set(reflect.Zero(val.Type()))
+		return
+	}
+
+	if i >= len(d.decoders) || d.decoders[i] == nil {
+		r.ReportError("decode union type", fmt.Sprintf("unknown union type %s for %s", schemaTypeName(schema), d.iface.String()))
+		return
+	}
+
+	typ := d.types[i]
+	newPtr := typ.UnsafeNew()
+	d.decoders[i].Decode(newPtr, r)
+	val.Set(reflect.NewAt(typ.Type1(), newPtr).Elem())
+}
+
 func unionResolutionName(schema Schema) string {
 	name := schemaTypeName(schema)
 	switch schema.Type() {
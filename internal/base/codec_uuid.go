@@ -0,0 +1,130 @@
+package base
+
+import (
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"unsafe"
+
+	"github.com/modern-go/reflect2"
+)
+
+// uuidPackages are the import paths of the packages whose UUID types are derived as uuids without the uuid tag.
+var uuidPackages = []string{"github.com/google/uuid", "github.com/gofrs/uuid"}
+
+// isUUIDType determines if the type is the [16]byte UUID of github.com/google/uuid or github.com/gofrs/uuid,
+// including its major versions. Other types are uuids with the uuid tag only.
+func isUUIDType(typ reflect2.Type) bool {
+	if typ.Kind() != reflect.Array || typ.Type1().Name() != "UUID" {
+		return false
+	}
+	arrayType := typ.(reflect2.ArrayType)
+	if arrayType.Elem().Kind() != reflect.Uint8 || arrayType.Len() != 16 {
+		return false
+	}
+	pkg := typ.Type1().PkgPath()
+	for _, known := range uuidPackages {
+		if pkg == known || strings.HasPrefix(pkg, known+"/v") {
+			return true
+		}
+	}
+	return false
+}
+
+func parseUUIDType(typ reflect2.Type) (Schema, error) {
+	name, ns := schemaNameOf(typ)
+	return NewFixedSchema(name, ns, 16, NewPrimitiveLogicalSchema(UUID))
+}
+
+// parseUUID parses the canonical form of an uuid, xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+func parseUUID(s []byte) (u [16]byte, err error) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		err = fmt.Errorf("avro: %q is not an uuid in canonical form", s)
+		return
+	}
+	offset := 0
+	for _, part := range [][2]int{{0, 8}, {9, 13}, {14, 18}, {19, 23}, {24, 36}} {
+		n, decodeErr := hex.Decode(u[offset:], s[part[0]:part[1]])
+		if decodeErr != nil {
+			err = fmt.Errorf("avro: %q is not an uuid in canonical form", s)
+			return
+		}
+		offset += n
+	}
+	return
+}
+
+func formatUUID(u []byte) string {
+	b := make([]byte, 36)
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b)
+}
+
+// uuidStringCodec validates the canonical form of uuid strings.
+type uuidStringCodec struct{}
+
+func (*uuidStringCodec) Decode(ptr unsafe.Pointer, r *Reader) {
+	s := r.ReadString()
+	if _, err := parseUUID([]byte(s)); err != nil {
+		r.ReportError("uuidStringCodec", err.Error())
+		return
+	}
+	*((*string)(ptr)) = s
+}
+
+func (*uuidStringCodec) Encode(ptr unsafe.Pointer, w *Writer) {
+	s := *((*string)(ptr))
+	if _, err := parseUUID([]byte(s)); err != nil {
+		w.Error = err
+		return
+	}
+	w.WriteString(s)
+}
+
+// uuidArrayCodec encodes [16]byte arrays as uuid strings.
+type uuidArrayCodec struct{}
+
+func (*uuidArrayCodec) Decode(ptr unsafe.Pointer, r *Reader) {
+	u, err := parseUUID(r.ReadBytes())
+	if err != nil {
+		r.ReportError("uuidArrayCodec", err.Error())
+		return
+	}
+	*((*[16]byte)(ptr)) = u
+}
+
+func (*uuidArrayCodec) Encode(ptr unsafe.Pointer, w *Writer) {
+	w.WriteString(formatUUID((*((*[16]byte)(ptr)))[:]))
+}
+
+// uuidTextCodec validates the canonical form of uuid strings before they are unmarshalled as text.
+type uuidTextCodec struct {
+	typ reflect2.Type
+}
+
+func (c uuidTextCodec) Decode(ptr unsafe.Pointer, r *Reader) {
+	b := r.ReadBytes()
+	if _, err := parseUUID(b); err != nil {
+		r.ReportError("uuidTextCodec", err.Error())
+		return
+	}
+	obj := c.typ.UnsafeIndirect(ptr)
+	if reflect2.IsNil(obj) {
+		ptrType := c.typ.(*reflect2.UnsafePtrType)
+		*((*unsafe.Pointer)(ptr)) = ptrType.Elem().UnsafeNew()
+		obj = c.typ.UnsafeIndirect(ptr)
+	}
+	if err := obj.(encoding.TextUnmarshaler).UnmarshalText(b); err != nil {
+		r.ReportError("uuidTextCodec", err.Error())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"golang.org/x/sync/singleflight"
 	"io"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 
 	"github.com/modern-go/reflect2"
@@ -13,8 +14,19 @@ import (
 
 const maxByteSliceSize = 1024 * 1024
 
-// DefaultConfig is the default API.
-var DefaultConfig = Config{}.Freeze()
+// DefaultConfig is the default API, whose derived schemas are cached in the DefaultSchemaCache.
+// It uses the config set by SetDefaultConfig, and is never reassigned.
+var DefaultConfig API = defaultAPI{}
+
+// defaultConfig is the frozen config of the DefaultConfig.
+var defaultConfig atomic.Pointer[frozenConfig]
+
+func init() {
+	defaultConfig.Store(Config{}.freeze(DefaultSchemaCache))
+}
+
+// defaultConfigMu serializes the replacements of the DefaultConfig.
+var defaultConfigMu sync.Mutex
 
 // Config customises how the codec should behave.
 type Config struct {
@@ -52,13 +64,36 @@ type Config struct {
 	// MaxByteSliceSize is the maximum size of `bytes` or `string` types the Reader will create, defaulting to 1MiB.
 	// If this size is exceeded, the Reader returns an error. This can be disabled by setting a negative number.
 	MaxByteSliceSize int
+
+	// UintStrategy is the Avro type of uint and uint64 in schemas derived from Go types, defaulting to UintFixed.
+	// Derived schemas follow the strategy of the config deriving them, see API.ParseValue.
+	UintStrategy UintStrategy
+
+	// LegacyRawFraming wraps the bytes of Marshaler types with a bytes schema in a second bytes, as in former
+	// versions, so that their data is read and written. By default they are a single bytes, as in other implementations.
+	LegacyRawFraming bool
+
+	// TimePrecision is the precision of timestamps in schemas derived from time.Time, defaulting to PrecisionMicros.
+	// Derived schemas follow the precision of the config deriving them, see API.ParseValue.
+	TimePrecision TimePrecision
+
+	// MapEntries derives maps as arrays of records of their key and value, named after the record and the field
+	// or after the map type, instead of Avro maps. Maps whose keys are not strings, integers or text marshalers
+	// are derived only as such arrays, with MapEntries or the entries tag option.
+	MapEntries bool
 }
 
 // Freeze makes the configuration immutable.
 func (c Config) Freeze() API {
+	return c.freeze(&SchemaCache{})
+}
+
+// freeze returns the frozen config, caching its derived schemas in schemas.
+func (c Config) freeze(schemas *SchemaCache) *frozenConfig {
 	api := &frozenConfig{
 		config:   c,
 		resolver: NewTypeResolver(),
+		schemas:  schemas,
 	}
 
 	api.readerPool = &sync.Pool{
@@ -89,6 +124,131 @@ func (c Config) Freeze() API {
 	return api
 }
 
+// SetDefaultConfig replaces the config of the DefaultConfig with the frozen c, keeping the registered types, codecs and
+// interfaces. Schemas derived by the former config are kept only if c derives the same schemas.
+//
+// It is safe to call concurrently with encoding and decoding, which use either the former or the new config.
+func SetDefaultConfig(c Config) {
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+
+	prev := defaultConfig.Load()
+	schemas := prev.schemas
+	if !prev.config.derivesLike(c) {
+		schemas = &SchemaCache{}
+	}
+	api := c.freeze(schemas)
+	api.resolver = prev.resolver
+	prev.codecs.Range(func(key, value any) bool {
+		api.codecs.Store(key, value)
+		return true
+	})
+	prev.interfaces.Range(func(key, value any) bool {
+		api.interfaces.Store(key, value)
+		return true
+	})
+	api.registered.values = append([]registeredType(nil), prev.registered.list()...)
+	defaultConfig.Store(api)
+}
+
+// frozenConfigOf returns the frozen config of the API, the current one of the DefaultConfig.
+func frozenConfigOf(api API) *frozenConfig {
+	if _, ok := api.(defaultAPI); ok {
+		return defaultConfig.Load()
+	}
+	return api.(*frozenConfig)
+}
+
+// defaultAPI is the API of the DefaultConfig, which delegates to its current frozen config.
+type defaultAPI struct{}
+
+func (defaultAPI) Marshal(schema Schema, v any) ([]byte, error) {
+	return defaultConfig.Load().Marshal(schema, v)
+}
+
+func (defaultAPI) Unmarshal(schema Schema, data []byte, v any) error {
+	return defaultConfig.Load().Unmarshal(schema, data, v)
+}
+
+func (defaultAPI) UnmarshalWithWriterSchema(schema, writer Schema, data []byte, v any) error {
+	return defaultConfig.Load().UnmarshalWithWriterSchema(schema, writer, data, v)
+}
+
+func (defaultAPI) MarshalSingleObject(schema Schema, v any) ([]byte, error) {
+	return defaultConfig.Load().MarshalSingleObject(schema, v)
+}
+
+func (defaultAPI) UnmarshalSingleObject(store SchemaStore, schema Schema, data []byte, v any) error {
+	return defaultConfig.Load().UnmarshalSingleObject(store, schema, data, v)
+}
+
+func (defaultAPI) MarshalGeneric(schema Schema, v any) ([]byte, error) {
+	return defaultConfig.Load().MarshalGeneric(schema, v)
+}
+
+func (defaultAPI) UnmarshalGeneric(schema Schema, data []byte) (any, error) {
+	return defaultConfig.Load().UnmarshalGeneric(schema, data)
+}
+
+func (defaultAPI) MarshalAvroJSON(schema Schema, v any) ([]byte, error) {
+	return defaultConfig.Load().MarshalAvroJSON(schema, v)
+}
+
+func (defaultAPI) UnmarshalAvroJSON(schema Schema, data []byte, v any) error {
+	return defaultConfig.Load().UnmarshalAvroJSON(schema, data, v)
+}
+
+func (defaultAPI) NewJSONEncoder(schema Schema, w io.Writer) *JSONEncoder {
+	return defaultConfig.Load().NewJSONEncoder(schema, w)
+}
+
+func (defaultAPI) NewJSONDecoder(schema Schema, r io.Reader) *JSONDecoder {
+	return defaultConfig.Load().NewJSONDecoder(schema, r)
+}
+
+func (defaultAPI) NewEncoder(schema Schema, w io.Writer) *Encoder {
+	return defaultConfig.Load().NewEncoder(schema, w)
+}
+
+func (defaultAPI) NewDecoder(schema Schema, r io.Reader) *Decoder {
+	return defaultConfig.Load().NewDecoder(schema, r)
+}
+
+func (defaultAPI) DecoderOf(schema Schema, typ reflect2.Type) ValDecoder {
+	return defaultConfig.Load().DecoderOf(schema, typ)
+}
+
+func (defaultAPI) EncoderOf(schema Schema, typ reflect2.Type) ValEncoder {
+	return defaultConfig.Load().EncoderOf(schema, typ)
+}
+
+func (defaultAPI) Register(name string, obj any) {
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig.Load().Register(name, obj)
+}
+
+func (defaultAPI) RegisterCodec(typ reflect2.Type, schema Schema, encoder ValEncoder, decoder ValDecoder) {
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig.Load().RegisterCodec(typ, schema, encoder, decoder)
+}
+
+func (defaultAPI) RegisterInterface(iface any, types ...any) {
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig.Load().RegisterInterface(iface, types...)
+}
+
+func (defaultAPI) ParseValue(v any) (Schema, error) {
+	return defaultConfig.Load().ParseValue(v)
+}
+
+// derivesLike determines if schemas derived with c and other are the same.
+func (c Config) derivesLike(other Config) bool {
+	return c.UintStrategy == other.UintStrategy && c.TimePrecision == other.TimePrecision && c.MapEntries == other.MapEntries
+}
+
 // API represents a frozen Config.
 type API interface {
 	// Marshal returns the Avro encoding of v.
@@ -98,6 +258,35 @@ type API interface {
 	// If v is nil or not a pointer, Unmarshal returns an error.
 	Unmarshal(schema Schema, data []byte, v any) error
 
+	// UnmarshalWithWriterSchema parses the Avro encoded data that was written with the writer schema,
+	// resolves it against the reader schema and stores the result in the value pointed to by v.
+	UnmarshalWithWriterSchema(schema, writer Schema, data []byte, v any) error
+
+	// MarshalSingleObject returns the Avro single object encoding of v.
+	MarshalSingleObject(schema Schema, v any) ([]byte, error)
+
+	// UnmarshalSingleObject parses the Avro single object encoded data, looking up the writer schema
+	// in the store, and stores the result in the value pointed to by v.
+	UnmarshalSingleObject(store SchemaStore, schema Schema, data []byte, v any) error
+
+	// MarshalGeneric returns the Avro encoding of the generic value v.
+	MarshalGeneric(schema Schema, v any) ([]byte, error)
+
+	// UnmarshalGeneric parses the Avro encoded data and returns the generic value of it.
+	UnmarshalGeneric(schema Schema, data []byte) (any, error)
+
+	// MarshalAvroJSON returns the Avro JSON encoding of v.
+	MarshalAvroJSON(schema Schema, v any) ([]byte, error)
+
+	// UnmarshalAvroJSON parses the Avro JSON encoded data and stores the result in the value pointed to by v.
+	UnmarshalAvroJSON(schema Schema, data []byte, v any) error
+
+	// NewJSONEncoder returns a new JSON encoder that writes to w using schema.
+	NewJSONEncoder(schema Schema, w io.Writer) *JSONEncoder
+
+	// NewJSONDecoder returns a new JSON decoder that reads from reader r using schema.
+	NewJSONDecoder(schema Schema, r io.Reader) *JSONDecoder
+
 	// NewEncoder returns a new encoder that writes to w using schema.
 	NewEncoder(schema Schema, w io.Writer) *Encoder
 
@@ -112,6 +301,15 @@ type API interface {
 
 	// Register registers names to their types for resolution. All primitive types are pre-registered.
 	Register(name string, obj any)
+
+	// RegisterCodec binds the encoder and decoder of a type to schema.
+	RegisterCodec(typ reflect2.Type, schema Schema, encoder ValEncoder, decoder ValDecoder)
+
+	// RegisterInterface registers the types making up the union of fields of the interface, given as a nil pointer to it.
+	RegisterInterface(iface any, types ...any)
+
+	// ParseValue derives the schema of v with the settings of the config, such as its UintStrategy and TimePrecision.
+	ParseValue(v any) (Schema, error)
 }
 
 type frozenConfig struct {
@@ -130,6 +328,15 @@ type frozenConfig struct {
 	writerPool *sync.Pool
 
 	resolver *TypeResolver
+
+	codecs sync.Map // map[uintptr]*registeredCodec
+
+	interfaces sync.Map // map[uintptr][]registeredType
+
+	registered registeredTypes
+
+	// schemas are the schemas derived from Go types with the settings of the config.
+	schemas *SchemaCache
 }
 
 func (c *frozenConfig) Marshal(schema Schema, v any) ([]byte, error) {
@@ -176,6 +383,20 @@ func (c *frozenConfig) Unmarshal(schema Schema, data []byte, v any) error {
 	return err
 }
 
+func (c *frozenConfig) UnmarshalWithWriterSchema(schema, writer Schema, data []byte, v any) error {
+	reader := c.borrowReader(data)
+
+	reader.ReadValWithWriterSchema(schema, writer, v)
+	err := reader.Error
+	c.returnReader(reader)
+
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	return err
+}
+
 func (c *frozenConfig) borrowReader(data []byte) *Reader {
 	reader := c.readerPool.Get().(*Reader)
 	reader.Reset(data)
@@ -48,3 +48,9 @@ func (d *Decoder) Decode(obj any) error {
 func Unmarshal(schema Schema, data []byte, v any) error {
 	return DefaultConfig.Unmarshal(schema, data, v)
 }
+
+// UnmarshalWithWriterSchema parses the Avro encoded data that was written with the writer schema,
+// resolves it against the reader schema and stores the result in the value pointed to by v.
+func UnmarshalWithWriterSchema(schema, writer Schema, data []byte, v any) error {
+	return DefaultConfig.UnmarshalWithWriterSchema(schema, writer, data, v)
+}
@@ -0,0 +1,39 @@
+package base
+
+import (
+	"errors"
+	"io"
+)
+
+// MarshalGeneric returns the Avro encoding of the generic value v.
+//
+// Generic values are the values returned by UnmarshalGeneric, records are map[string]any,
+// unions are nil or a map of the union type name to the value, and logical types are
+// time.Time, time.Duration and *big.Rat.
+func (c *frozenConfig) MarshalGeneric(schema Schema, v any) ([]byte, error) {
+	return c.Marshal(schema, v)
+}
+
+// UnmarshalGeneric parses the Avro encoded data and returns the generic value of it.
+func (c *frozenConfig) UnmarshalGeneric(schema Schema, data []byte) (any, error) {
+	reader := c.borrowReader(data)
+
+	v := reader.ReadNext(schema)
+	err := reader.Error
+	c.returnReader(reader)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return v, nil
+}
+
+// MarshalGeneric returns the Avro encoding of the generic value v.
+func MarshalGeneric(schema Schema, v any) ([]byte, error) {
+	return DefaultConfig.MarshalGeneric(schema, v)
+}
+
+// UnmarshalGeneric parses the Avro encoded data and returns the generic value of it.
+func UnmarshalGeneric(schema Schema, data []byte) (any, error) {
+	return DefaultConfig.UnmarshalGeneric(schema, data)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/mitchellh/mapstructure"
@@ -17,8 +18,9 @@ var (
 )
 
 type protocolConfig struct {
-	doc   string
-	props map[string]any
+	doc    string
+	props  map[string]any
+	errors []error
 }
 
 // ProtocolOption is a function that sets a protocol option.
@@ -102,7 +104,10 @@ func (p *Protocol) Types() []NamedSchema {
 	return p.types
 }
 
-// String returns the canonical form of the protocol.
+// String returns the canonical form of the protocol, whose MD5 is the hash of the protocol.
+//
+// It is the form of former versions, where null responses and one-way messages are left out, so that
+// hashes match the hashes of their peers. See Declaration for the protocol in full.
 func (p *Protocol) String() string {
 	types := ""
 	for _, f := range p.types {
@@ -112,9 +117,10 @@ func (p *Protocol) String() string {
 		types = types[:len(types)-1]
 	}
 
+	// Messages are sorted for a stable hash, an order former versions had by chance
 	messages := ""
-	for k, m := range p.messages {
-		messages += `"` + k + `":` + m.String() + ","
+	for _, k := range p.messageNames() {
+		messages += `"` + k + `":` + p.messages[k].String() + ","
 	}
 	if len(messages) > 0 {
 		messages = messages[:len(messages)-1]
@@ -125,6 +131,41 @@ func (p *Protocol) String() string {
 		`","types":[` + types + `],"messages":{` + messages + `}}`
 }
 
+// Declaration returns the protocol as declared in .avpr files, which is parsed to the same protocol.
+// Named types are defined once, messages are sorted, and null responses and one-way messages are explicit.
+func (p *Protocol) Declaration() string {
+	seen := seenCache{}
+	types := ""
+	for _, f := range p.types {
+		types += canonicalString(f, seen) + ","
+	}
+	if len(types) > 0 {
+		types = types[:len(types)-1]
+	}
+
+	messages := ""
+	for _, k := range p.messageNames() {
+		messages += `"` + k + `":` + p.messages[k].declaration(seen) + ","
+	}
+	if len(messages) > 0 {
+		messages = messages[:len(messages)-1]
+	}
+
+	return `{"protocol":"` + p.Name() +
+		`","namespace":"` + p.Namespace() +
+		`","types":[` + types + `],"messages":{` + messages + `}}`
+}
+
+// messageNames returns the sorted names of the messages.
+func (p *Protocol) messageNames() []string {
+	keys := make([]string, 0, len(p.messages))
+	for k := range p.messages {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Message is an Avro protocol message.
 type Message struct {
 	properties
@@ -201,6 +242,33 @@ func (m *Message) String() string {
 	return str
 }
 
+// declaration returns the message as declared in protocols, see Protocol.Declaration.
+func (m *Message) declaration(seen seenCache) string {
+	fields := ""
+	for _, f := range m.req.fields {
+		fields += f.canonical(seen) + ","
+	}
+	if len(fields) > 0 {
+		fields = fields[:len(fields)-1]
+	}
+
+	str := `{"request":[` + fields + `]`
+	if m.resp != nil {
+		str += `,"response":` + canonicalString(m.resp, seen)
+	} else if !m.oneWay {
+		str += `,"response":"null"`
+	}
+	if m.errs != nil && len(m.errs.Types()) > 1 {
+		errs, _ := NewUnionSchema(m.errs.Types()[1:])
+		str += `,"errors":` + errs.canonical(seen)
+	}
+	if m.oneWay {
+		str += `,"one-way":true`
+	}
+	str += "}"
+	return str
+}
+
 // ParseProtocolFile parses an Avro protocol from a file.
 func ParseProtocolFile(path string) (*Protocol, error) {
 	s, err := os.ReadFile(path)
@@ -269,8 +337,16 @@ func parseProtocol(m map[string]any, seen seenCache, cache *SchemaCache) (*Proto
 
 	messages := map[string]*Message{}
 	if len(p.Messages) > 0 {
-		for k, msg := range p.Messages {
-			message, err := parseMessage(p.Namespace, msg, seen, cache)
+		// Messages are parsed in the order of their names, the order of the canonical form,
+		// as the types defined by a message may be referred to by the next ones
+		keys := make([]string, 0, len(p.Messages))
+		for k := range p.Messages {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			msg := p.Messages[k]
+			message, err := parseMessage(k, p.Namespace, msg, seen, cache)
 			if err != nil {
 				return nil, err
 			}
@@ -310,7 +386,7 @@ type message struct {
 	Props    map[string]any   `mapstructure:",remain"`
 }
 
-func parseMessage(namespace string, m map[string]any, seen seenCache, cache *SchemaCache) (*Message, error) {
+func parseMessage(msgName, namespace string, m map[string]any, seen seenCache, cache *SchemaCache) (*Message, error) {
 	var (
 		msg  message
 		meta mapstructure.Metadata
@@ -327,10 +403,10 @@ func parseMessage(namespace string, m map[string]any, seen seenCache, cache *Sch
 		}
 		fields[i] = field
 	}
-	request := &RecordSchema{
-		name:       name{},
-		properties: properties{},
-		fields:     fields,
+	// The request is a record of the fields, named after the message
+	request, err := NewRecordSchema(msgName, "", fields)
+	if err != nil {
+		return nil, err
 	}
 
 	var response Schema
@@ -369,7 +445,7 @@ func parseMessage(namespace string, m map[string]any, seen seenCache, cache *Sch
 	if hasKey(meta.Keys, "one-way") && oneWay && (len(errs.Types()) > 1 || response != nil) {
 		return nil, errors.New("avro: one-way messages cannot not have a response or errors")
 	}
-	if !oneWay && len(errs.Types()) <= 1 && response == nil {
+	if !oneWay && len(errs.Types()) <= 1 && response == nil && !hasKey(meta.Keys, "response") {
 		oneWay = true
 	}
 
@@ -0,0 +1,170 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/modern-go/reflect2"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// WithErrors sets the errors of the messages of protocols derived by ProtocolOf, in the given order.
+// The error record of each error has the name and the fields of the derived record of its type, unless its schema
+// is already an error record, declared by a SchemaProvider or a registered codec.
+func WithErrors(errs ...error) ProtocolOption {
+	return func(opts *protocolConfig) {
+		opts.errors = errs
+	}
+}
+
+// errorRecordOf returns the error record of the type of the error.
+func errorRecordOf(v error) (*RecordSchema, error) {
+	s, err := ParseValue(v)
+	if err != nil {
+		return nil, err
+	}
+	if ref, ok := s.(*RefSchema); ok {
+		s = ref.Schema()
+	}
+	rec, ok := s.(*RecordSchema)
+	if !ok {
+		return nil, fmt.Errorf("avro: error %T is not a record", v)
+	}
+	if rec.IsError() {
+		return rec, nil
+	}
+	return NewErrorRecordSchema(rec.Name(), rec.Namespace(), rec.Fields(), WithAliases(rec.Aliases()), WithDoc(rec.Doc()))
+}
+
+// ProtocolOf derives the protocol of an interface, given as a nil pointer to it: ProtocolOf((*Service)(nil)).
+//
+// Each method is a message named after the method, with its first letter in lower case.
+// Methods are of the forms
+//
+//	Foo(ctx context.Context, req Req) (Resp, error)  // a message of the fields of Req and of response Resp
+//	Foo(ctx context.Context, req Req) (*Resp, error) // a message of response ["null", Resp]
+//	Foo(ctx context.Context, req Req) error           // a message of null response
+//	Foo(ctx context.Context, req Req)                 // a one-way message
+//
+// where the context and the request parameter are optional, and Req is a struct or a pointer to one.
+// Responses are derived as fields are, pointers being nullable.
+// The errors of messages, except one-way messages, are the errors set by WithErrors, after the string
+// of undeclared errors which is the only error of one-way messages, as in parsed protocols.
+func ProtocolOf(v any, opts ...ProtocolOption) (*Protocol, error) {
+	typ := reflect.TypeOf(v)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Interface {
+		return nil, fmt.Errorf("avro: ProtocolOf expects a pointer to an interface, got %T", v)
+	}
+	iface := typ.Elem()
+	name, ns := schemaNameOf(reflect2.Type2(iface))
+
+	var cfg protocolConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	errs := []Schema{NewPrimitiveSchema(String, nil)}
+	var types []NamedSchema
+	for _, e := range cfg.errors {
+		rec, err := errorRecordOf(e)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, rec)
+		errs = append(errs, rec)
+	}
+	errUnion, err := NewUnionSchema(errs)
+	if err != nil {
+		return nil, err
+	}
+
+	oneWayErrUnion, err := NewUnionSchema([]Schema{NewPrimitiveSchema(String, nil)})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := map[string]*Message{}
+	for i := 0; i < iface.NumMethod(); i++ {
+		method := iface.Method(i)
+		msgName := messageNameOf(method.Name)
+		msg, msgErr := parseMethod(msgName, method, errUnion, oneWayErrUnion)
+		if msgErr != nil {
+			return nil, fmt.Errorf("avro: parse %s.%s failed, %v", iface.String(), method.Name, msgErr)
+		}
+		messages[msgName] = msg
+	}
+	return NewProtocol(name, ns, types, messages, opts...)
+}
+
+// parseMethod returns the message of the method, of the errors, or of the oneWayErrs if it is one-way.
+func parseMethod(msgName string, method reflect.Method, errs, oneWayErrs *UnionSchema) (*Message, error) {
+	mt := method.Type
+
+	in := 0
+	if mt.NumIn() > 0 && mt.In(0) == contextType {
+		in = 1
+	}
+	var fields []*Field
+	switch mt.NumIn() - in {
+	case 0:
+	case 1:
+		reqType := mt.In(in)
+		if reqType.Kind() == reflect.Ptr {
+			reqType = reqType.Elem()
+		}
+		if reqType.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("request %s is not a struct", reqType.String())
+		}
+		s, err := ParseValue(reflect.New(reqType).Interface())
+		if err != nil {
+			return nil, err
+		}
+		if ref, ok := s.(*RefSchema); ok {
+			s = ref.Schema()
+		}
+		rec, ok := s.(*RecordSchema)
+		if !ok {
+			return nil, fmt.Errorf("request %s is not a record", reqType.String())
+		}
+		fields = rec.Fields()
+	default:
+		return nil, fmt.Errorf("more than one request parameter")
+	}
+	req, err := NewRecordSchema(msgName, "", fields)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mt.NumOut() {
+	case 0:
+		return NewMessage(req, nil, oneWayErrs, true), nil
+	case 1:
+		if mt.Out(0) != errorType {
+			return nil, fmt.Errorf("last result is not an error")
+		}
+		return NewMessage(req, nil, errs, false), nil
+	case 2:
+		if mt.Out(1) != errorType {
+			return nil, fmt.Errorf("last result is not an error")
+		}
+		resp, err := ParseValue(reflect.New(mt.Out(0)).Interface())
+		if err != nil {
+			return nil, err
+		}
+		return NewMessage(req, resp, errs, false), nil
+	default:
+		return nil, fmt.Errorf("more than two results")
+	}
+}
+
+// messageNameOf returns the method name with its first letter in lower case.
+func messageNameOf(method string) string {
+	r, size := utf8.DecodeRuneInString(method)
+	return string(unicode.ToLower(r)) + method[size:]
+}
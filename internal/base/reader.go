@@ -19,7 +19,7 @@ type ReaderFunc func(r *Reader)
 // WithReaderConfig specifies the configuration to use with a reader.
 func WithReaderConfig(cfg API) ReaderFunc {
 	return func(r *Reader) {
-		r.cfg = cfg.(*frozenConfig)
+		r.cfg = frozenConfigOf(cfg)
 	}
 }
 
@@ -37,7 +37,7 @@ type Reader struct {
 // NewReader creates a new Reader.
 func NewReader(r io.Reader, bufSize int, opts ...ReaderFunc) *Reader {
 	reader := &Reader{
-		cfg:    DefaultConfig.(*frozenConfig),
+		cfg:    defaultConfig.Load(),
 		reader: r,
 		buf:    make([]byte, bufSize),
 		head:   0,
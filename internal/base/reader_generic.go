@@ -47,6 +47,24 @@ func (r *Reader) ReadNext(schema Schema) any {
 				sec := i / 1e6
 				nsec := (i - sec*1e6) * 1e3
 				return time.Unix(sec, nsec).UTC()
+
+			case TimestampNanos:
+				return time.Unix(0, r.ReadLong()).UTC()
+
+			case LocalTimestampMillis:
+				i := r.ReadLong()
+				sec := i / 1e3
+				nsec := (i - sec*1e3) * 1e6
+				return fromTimestamp(time.Unix(sec, nsec), true)
+
+			case LocalTimestampMicros:
+				i := r.ReadLong()
+				sec := i / 1e6
+				nsec := (i - sec*1e6) * 1e3
+				return fromTimestamp(time.Unix(sec, nsec), true)
+
+			case LocalTimestampNanos:
+				return fromTimestamp(time.Unix(0, r.ReadLong()), true)
 			}
 		}
 		return r.ReadLong()
@@ -62,7 +80,7 @@ func (r *Reader) ReadNext(schema Schema) any {
 			return ratFromBytes(r.ReadBytes(), dec.Scale())
 		}
 		return r.ReadBytes()
-	case Record:
+	case Record, Error:
 		fields := schema.(*RecordSchema).Fields()
 		obj := make(map[string]any, len(fields))
 		for _, field := range fields {
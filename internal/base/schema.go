@@ -75,6 +75,11 @@ const (
 	TimestampMillis LogicalType = "timestamp-millis"
 	TimestampMicros LogicalType = "timestamp-micros"
 	Duration        LogicalType = "duration"
+
+	TimestampNanos       LogicalType = "timestamp-nanos"
+	LocalTimestampMillis LogicalType = "local-timestamp-millis"
+	LocalTimestampMicros LogicalType = "local-timestamp-micros"
+	LocalTimestampNanos  LogicalType = "local-timestamp-nanos"
 )
 
 // FingerprintType is a fingerprinting algorithm.
@@ -87,10 +92,10 @@ const (
 	SHA256    FingerprintType = "SHA256"
 )
 
-var fingerprinters = map[FingerprintType]hash.Hash{
-	CRC64Avro: crc64.New(),
-	MD5:       md5.New(),
-	SHA256:    sha256.New(),
+var fingerprinters = map[FingerprintType]func() hash.Hash{
+	CRC64Avro: func() hash.Hash { return crc64.New() },
+	MD5:       md5.New,
+	SHA256:    sha256.New,
 }
 
 // SchemaCache is a cache of schemas.
@@ -156,6 +161,19 @@ type Schema interface {
 	FingerprintUsing(FingerprintType) ([]byte, error)
 }
 
+// canonicalSchema is a schema whose canonical form depends on the named
+// schemas already written, so that each named schema is defined only once.
+type canonicalSchema interface {
+	canonical(seen seenCache) string
+}
+
+func canonicalString(schema Schema, seen seenCache) string {
+	if c, ok := schema.(canonicalSchema); ok {
+		return c.canonical(seen)
+	}
+	return schema.String()
+}
+
 // LogicalSchema represents an Avro schema with a logical type.
 type LogicalSchema interface {
 	// Type returns the type of the logical schema.
@@ -288,12 +306,12 @@ func (f *fingerprinter) FingerprintUsing(typ FingerprintType, stringer fmt.Strin
 		return v.([]byte), nil
 	}
 
-	h, ok := fingerprinters[typ]
+	newHash, ok := fingerprinters[typ]
 	if !ok {
 		return nil, fmt.Errorf("avro: unknown fingerprint algorithm %s", typ)
 	}
 
-	h.Reset()
+	h := newHash()
 	_, _ = h.Write([]byte(stringer.String()))
 	fingerprint := h.Sum(make([]byte, 0, h.Size()))
 	f.cache.Store(typ, fingerprint)
@@ -537,6 +555,14 @@ func (s *RecordSchema) Fields() []*Field {
 
 // String returns the canonical form of the schema.
 func (s *RecordSchema) String() string {
+	return s.canonical(seenCache{})
+}
+
+func (s *RecordSchema) canonical(seen seenCache) string {
+	if seen.Add(s.FullName()) != nil {
+		return `"` + s.FullName() + `"`
+	}
+
 	typ := "record"
 	if s.isError {
 		typ = "error"
@@ -544,7 +570,7 @@ func (s *RecordSchema) String() string {
 
 	fields := ""
 	for _, f := range s.fields {
-		fields += f.String() + ","
+		fields += f.canonical(seen) + ","
 	}
 	if len(fields) > 0 {
 		fields = fields[:len(fields)-1]
@@ -702,7 +728,11 @@ func (f *Field) Order() Order {
 
 // String returns the canonical form of a field.
 func (f *Field) String() string {
-	return `{"name":"` + f.name + `","type":` + f.typ.String() + `}`
+	return f.canonical(seenCache{})
+}
+
+func (f *Field) canonical(seen seenCache) string {
+	return `{"name":"` + f.name + `","type":` + canonicalString(f.typ, seen) + `}`
 }
 
 // MarshalJSON marshals the schema to json.
@@ -825,6 +855,14 @@ func (s *EnumSchema) Default() string {
 
 // String returns the canonical form of the schema.
 func (s *EnumSchema) String() string {
+	return s.canonical(seenCache{})
+}
+
+func (s *EnumSchema) canonical(seen seenCache) string {
+	if seen.Add(s.FullName()) != nil {
+		return `"` + s.FullName() + `"`
+	}
+
 	symbols := ""
 	for _, sym := range s.symbols {
 		symbols += `"` + sym + `",`
@@ -911,7 +949,11 @@ func (s *ArraySchema) Items() Schema {
 
 // String returns the canonical form of the schema.
 func (s *ArraySchema) String() string {
-	return `{"type":"array","items":` + s.items.String() + `}`
+	return s.canonical(seenCache{})
+}
+
+func (s *ArraySchema) canonical(seen seenCache) string {
+	return `{"type":"array","items":` + canonicalString(s.items, seen) + `}`
 }
 
 // MarshalJSON marshals the schema to json.
@@ -974,7 +1016,11 @@ func (s *MapSchema) Values() Schema {
 
 // String returns the canonical form of the schema.
 func (s *MapSchema) String() string {
-	return `{"type":"map","values":` + s.values.String() + `}`
+	return s.canonical(seenCache{})
+}
+
+func (s *MapSchema) canonical(seen seenCache) string {
+	return `{"type":"map","values":` + canonicalString(s.values, seen) + `}`
 }
 
 // MarshalJSON marshals the schema to json.
@@ -1066,9 +1112,13 @@ func (s *UnionSchema) Indices() (null, typ int) {
 
 // String returns the canonical form of the schema.
 func (s *UnionSchema) String() string {
+	return s.canonical(seenCache{})
+}
+
+func (s *UnionSchema) canonical(seen seenCache) string {
 	types := ""
 	for _, typ := range s.types {
-		types += typ.String() + ","
+		types += canonicalString(typ, seen) + ","
 	}
 	if len(types) > 0 {
 		types = types[:len(types)-1]
@@ -1144,6 +1194,14 @@ func (s *FixedSchema) Logical() LogicalSchema {
 
 // String returns the canonical form of the schema.
 func (s *FixedSchema) String() string {
+	return s.canonical(seenCache{})
+}
+
+func (s *FixedSchema) canonical(seen seenCache) string {
+	if seen.Add(s.FullName()) != nil {
+		return `"` + s.FullName() + `"`
+	}
+
 	size := strconv.Itoa(s.size)
 
 	var logical string
@@ -1251,6 +1309,14 @@ func (s *RefSchema) String() string {
 	return `"` + s.actual.FullName() + `"`
 }
 
+func (s *RefSchema) canonical(seen seenCache) string {
+	if _, ok := seen[s.actual.FullName()]; ok {
+		return `"` + s.actual.FullName() + `"`
+	}
+	// The referenced schema has not been defined yet, so define it here.
+	return canonicalString(s.actual, seen)
+}
+
 // MarshalJSON marshals the schema to json.
 func (s *RefSchema) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + s.actual.FullName() + `"`), nil
@@ -1380,10 +1446,15 @@ func isValidDefault(schema Schema, def any) (any, bool) {
 			}
 		}
 		return def, found
-	case String, Bytes, Fixed:
+	case String:
 		if _, ok := def.(string); ok {
 			return def, true
 		}
+	case Bytes, Fixed:
+		if v, ok := def.(string); ok {
+			_, ok = bytesOfDefault(v)
+			return def, ok
+		}
 	case Boolean:
 		if _, ok := def.(bool); ok {
 			return def, true
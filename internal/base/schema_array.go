@@ -1,10 +1,32 @@
 package base
 
 import (
+	"reflect"
+	"strconv"
+
 	"github.com/modern-go/reflect2"
 )
 
-func parseArrayType(typ reflect2.Type) (s Schema, err error) {
-	s, err = parseSliceType(typ)
+// parseArrayType returns the fixed schema of byte arrays, named after the type, or the array schema of other arrays.
+func parseArrayType(cfg *frozenConfig, typ reflect2.Type) (s Schema, err error) {
+	arrayType := typ.(reflect2.ArrayType)
+	if arrayType.Elem().Kind() == reflect.Uint8 {
+		if typ.Type1().Name() == "" {
+			return NewFixedSchema("fixed"+strconv.Itoa(arrayType.Len()), "go", arrayType.Len(), nil)
+		}
+		name, ns := schemaNameOf(typ)
+		return NewFixedSchema(name, ns, arrayType.Len(), nil)
+	}
+	elemSchema, elemErr := parseValueType(cfg, arrayType.Elem())
+	if elemErr != nil {
+		err = elemErr
+		return
+	}
+	s = NewArraySchema(elemSchema)
 	return
 }
+
+// isFixedArrayType determines if the type is an unnamed byte array, whose fixed schema is named after the field of it.
+func isFixedArrayType(typ reflect2.Type) bool {
+	return typ.Kind() == reflect.Array && typ.Type1().Name() == "" && typ.(reflect2.ArrayType).Elem().Kind() == reflect.Uint8
+}
@@ -0,0 +1,305 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CompatibilityMode is a registry style mode of schema evolution.
+type CompatibilityMode string
+
+// Compatibility modes.
+const (
+	// CompatibilityNone checks nothing.
+	CompatibilityNone CompatibilityMode = "NONE"
+	// CompatibilityBackward checks that the schema reads data of the latest version.
+	CompatibilityBackward CompatibilityMode = "BACKWARD"
+	// CompatibilityBackwardTransitive checks that the schema reads data of all versions.
+	CompatibilityBackwardTransitive CompatibilityMode = "BACKWARD_TRANSITIVE"
+	// CompatibilityForward checks that the latest version reads data of the schema.
+	CompatibilityForward CompatibilityMode = "FORWARD"
+	// CompatibilityForwardTransitive checks that all versions read data of the schema.
+	CompatibilityForwardTransitive CompatibilityMode = "FORWARD_TRANSITIVE"
+	// CompatibilityFull checks both backward and forward against the latest version.
+	CompatibilityFull CompatibilityMode = "FULL"
+	// CompatibilityFullTransitive checks both backward and forward against all versions.
+	CompatibilityFullTransitive CompatibilityMode = "FULL_TRANSITIVE"
+)
+
+// Incompatibility is an incompatible change of the schema from a version.
+type Incompatibility struct {
+	// Version is the index of the version in the checked versions.
+	Version int
+	// Direction is CompatibilityBackward if the schema cannot read the version,
+	// or CompatibilityForward if the version cannot read the schema.
+	Direction CompatibilityMode
+	// Path is the path of the change, such as Order.items[].price.
+	Path string
+	// Message describes the change, such as type changed long→string.
+	Message string
+}
+
+// String returns the path and message of the incompatibility.
+func (i Incompatibility) String() string {
+	return i.Path + ": " + i.Message
+}
+
+// CompatibilityReport lists the incompatibilities of a schema with its versions.
+type CompatibilityReport struct {
+	Mode              CompatibilityMode
+	Incompatibilities []Incompatibility
+}
+
+// Compatible determines if the report has no incompatibilities.
+func (r *CompatibilityReport) Compatible() bool {
+	return len(r.Incompatibilities) == 0
+}
+
+// String returns the incompatibilities, one per line.
+func (r *CompatibilityReport) String() string {
+	lines := make([]string, 0, len(r.Incompatibilities))
+	for _, i := range r.Incompatibilities {
+		lines = append(lines, fmt.Sprintf("version %d %s: %s", i.Version, strings.ToLower(string(i.Direction)), i))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Err returns an error listing the incompatibilities, or nil if the schemas are compatible.
+func (r *CompatibilityReport) Err() error {
+	if r.Compatible() {
+		return nil
+	}
+	return fmt.Errorf("avro: schema is not %s compatible\n%s", r.Mode, r.String())
+}
+
+// Check checks the schema against the versions, ordered from the oldest to the latest, in the mode.
+func (c *SchemaCompatibility) Check(mode CompatibilityMode, schema Schema, versions []Schema) (*CompatibilityReport, error) {
+	var backward, forward, transitive bool
+	switch mode {
+	case CompatibilityNone:
+	case CompatibilityBackward:
+		backward = true
+	case CompatibilityBackwardTransitive:
+		backward, transitive = true, true
+	case CompatibilityForward:
+		forward = true
+	case CompatibilityForwardTransitive:
+		forward, transitive = true, true
+	case CompatibilityFull:
+		backward, forward = true, true
+	case CompatibilityFullTransitive:
+		backward, forward, transitive = true, true, true
+	default:
+		return nil, fmt.Errorf("avro: unknown compatibility mode %q", mode)
+	}
+	if schema == nil {
+		return nil, errors.New("avro: schema is nil")
+	}
+
+	report := &CompatibilityReport{Mode: mode}
+	first := len(versions) - 1
+	if transitive {
+		first = 0
+	}
+	for version := first; version >= 0 && version < len(versions); version++ {
+		if backward {
+			r := &compatReporter{version: version, direction: CompatibilityBackward, visiting: map[compatKey]bool{}}
+			r.check(schema, versions[version], rootPath(schema))
+			report.Incompatibilities = append(report.Incompatibilities, r.issues...)
+		}
+		if forward {
+			r := &compatReporter{version: version, direction: CompatibilityForward, visiting: map[compatKey]bool{}}
+			r.check(versions[version], schema, rootPath(schema))
+			report.Incompatibilities = append(report.Incompatibilities, r.issues...)
+		}
+	}
+	return report, nil
+}
+
+func rootPath(s Schema) string {
+	if ref, ok := s.(*RefSchema); ok {
+		s = ref.Schema()
+	}
+	if named, ok := s.(NamedSchema); ok {
+		return named.Name()
+	}
+	return string(s.Type())
+}
+
+// compatReporter collects every incompatibility of the reader and writer schemas, where the
+// writer is the old schema of backward checks and the reader is the old schema of forward checks.
+type compatReporter struct {
+	version   int
+	direction CompatibilityMode
+	visiting  map[compatKey]bool
+	issues    []Incompatibility
+}
+
+func (r *compatReporter) add(path, format string, args ...any) {
+	r.issues = append(r.issues, Incompatibility{
+		Version:   r.version,
+		Direction: r.direction,
+		Path:      path,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+// changed returns the old and the new of the reader and writer values.
+func (r *compatReporter) changed(reader, writer string) (string, string) {
+	if r.direction == CompatibilityBackward {
+		return writer, reader
+	}
+	return reader, writer
+}
+
+// matches determines if the reader reads the writer, without reporting.
+func (r *compatReporter) matches(reader, writer Schema) bool {
+	sub := &compatReporter{version: r.version, direction: r.direction, visiting: r.visiting}
+	sub.check(reader, writer, "")
+	return len(sub.issues) == 0
+}
+
+func (r *compatReporter) check(reader, writer Schema, path string) {
+	if reader.Type() == Ref {
+		reader = reader.(*RefSchema).Schema()
+	}
+	if writer.Type() == Ref {
+		writer = writer.(*RefSchema).Schema()
+	}
+
+	// Recursive schemas are checked once per path down
+	key := compatKey{reader: reader.Fingerprint(), writer: writer.Fingerprint()}
+	if r.visiting[key] {
+		return
+	}
+	r.visiting[key] = true
+	defer delete(r.visiting, key)
+
+	if writer.Type() == Union {
+		// Reader must read all types of the writer
+		for _, schema := range writer.(*UnionSchema).Types() {
+			r.check(reader, schema, path)
+		}
+		return
+	}
+	if reader.Type() == Union {
+		// Reader must read the writer with one of its types
+		for _, schema := range reader.(*UnionSchema).Types() {
+			if r.matches(schema, writer) {
+				return
+			}
+		}
+		if r.direction == CompatibilityBackward {
+			r.add(path, "type %s removed from union", compatTypeName(writer))
+		} else {
+			r.add(path, "type %s added to union", compatTypeName(writer))
+		}
+		return
+	}
+
+	if reader.Type() != writer.Type() {
+		if !isPromotable(writer.Type(), reader.Type()) {
+			from, to := r.changed(compatTypeName(reader), compatTypeName(writer))
+			r.add(path, "type changed %s→%s", from, to)
+		}
+		return
+	}
+
+	switch reader.Type() {
+	case Array:
+		r.check(reader.(*ArraySchema).Items(), writer.(*ArraySchema).Items(), path+"[]")
+
+	case Map:
+		r.check(reader.(*MapSchema).Values(), writer.(*MapSchema).Values(), path+"{}")
+
+	case Fixed:
+		rs, ws := reader.(*FixedSchema), writer.(*FixedSchema)
+		if !r.checkName(rs, ws, path) {
+			return
+		}
+		if rs.Size() != ws.Size() {
+			from, to := r.changed(fmt.Sprint(rs.Size()), fmt.Sprint(ws.Size()))
+			r.add(path, "size changed %s→%s", from, to)
+		}
+
+	case Enum:
+		rs, ws := reader.(*EnumSchema), writer.(*EnumSchema)
+		if !r.checkName(rs, ws, path) {
+			return
+		}
+		if rs.Default() != "" {
+			// Unknown symbols are read as the default
+			return
+		}
+		for _, symbol := range ws.Symbols() {
+			if containsString(rs.Symbols(), symbol) {
+				continue
+			}
+			if r.direction == CompatibilityBackward {
+				r.add(path, "symbol %s removed", symbol)
+			} else {
+				r.add(path, "symbol %s added", symbol)
+			}
+		}
+
+	case Record:
+		rs, ws := reader.(*RecordSchema), writer.(*RecordSchema)
+		if !r.checkName(rs, ws, path) {
+			return
+		}
+		for _, field := range rs.Fields() {
+			f, ok := writerFieldOf(ws, field)
+			if !ok {
+				if field.HasDefault() {
+					continue
+				}
+				if r.direction == CompatibilityBackward {
+					r.add(path+"."+field.Name(), "field added without default")
+				} else {
+					r.add(path+"."+field.Name(), "field removed without default")
+				}
+				continue
+			}
+			r.check(field.Type(), f.Type(), path+"."+field.Name())
+		}
+	}
+}
+
+func (r *compatReporter) checkName(reader, writer NamedSchema, path string) bool {
+	if reader.FullName() == writer.FullName() {
+		return true
+	}
+	if aliased, ok := reader.(interface{ Aliases() []string }); ok && containsString(aliased.Aliases(), writer.FullName()) {
+		return true
+	}
+	from, to := r.changed(reader.FullName(), writer.FullName())
+	r.add(path, "name changed %s→%s", from, to)
+	return false
+}
+
+func compatTypeName(s Schema) string {
+	if named, ok := s.(NamedSchema); ok {
+		return named.FullName()
+	}
+	return string(s.Type())
+}
+
+// writerFieldOf returns the writer field of the name or the aliases of the reader field.
+func writerFieldOf(writer *RecordSchema, field *Field) (*Field, bool) {
+	for _, f := range writer.Fields() {
+		if f.Name() == field.Name() || containsString(field.Aliases(), f.Name()) {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+func containsString(a []string, s string) bool {
+	for _, str := range a {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package base
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/modern-go/reflect2"
+)
+
+// AvroEnum is implemented by types encoded as Avro enums, such as string types of symbol constants,
+// types implementing encoding.TextMarshaler and encoding.TextUnmarshaler, or integer types of the
+// index of their symbol.
+type AvroEnum interface {
+	AvroSymbols() []string
+}
+
+// AvroEnumDefault is implemented by enums having a default symbol, which is decoded instead of the symbols
+// of writer schemas unknown to the reader.
+type AvroEnumDefault interface {
+	AvroEnumDefault() string
+}
+
+var avroEnumType = reflect2.TypeOfPtr((*AvroEnum)(nil)).Elem()
+
+// isEnumType determines if the type implements AvroEnum.
+func isEnumType(typ reflect2.Type) bool {
+	return typ.Implements(avroEnumType) || reflect2.PtrTo(typ).Implements(avroEnumType)
+}
+
+// parseEnumType returns the enum schema of the symbols of the type, named after the type.
+func parseEnumType(typ reflect2.Type) (Schema, error) {
+	v := reflect.New(typ.Type1())
+	enum, ok := v.Interface().(AvroEnum)
+	if !ok {
+		enum = v.Elem().Interface().(AvroEnum)
+	}
+	var opts []SchemaOption
+	if def, hasDef := enum.(AvroEnumDefault); hasDef {
+		opts = append(opts, WithDefault(def.AvroEnumDefault()))
+	}
+	name, ns := schemaNameOf(typ)
+	s, err := NewEnumSchema(name, ns, enum.AvroSymbols(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("avro: parse enum %s failed, %v", typ.String(), err)
+	}
+	return s, nil
+}
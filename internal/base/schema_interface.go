@@ -0,0 +1,152 @@
+package base
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/modern-go/reflect2"
+)
+
+type registeredType struct {
+	typ    reflect2.Type
+	schema Schema
+}
+
+// registeredTypes are the types registered by RegisterSchemaByValue, in order of registration.
+// Interfaces without types given to RegisterInterface are mapped to a union of the registered types implementing them.
+type registeredTypes struct {
+	mu     sync.RWMutex
+	values []registeredType
+}
+
+func (r *registeredTypes) add(typ reflect2.Type, schema Schema) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values = append(r.values, registeredType{typ: typ, schema: schema})
+}
+
+func (r *registeredTypes) list() []registeredType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.values
+}
+
+// registerValue derives the schema of v and adds v to the unions of the interfaces it implements,
+// unless its schema can not be told apart in a union.
+func (c *frozenConfig) registerValue(v any) {
+	schema, err := c.ParseValue(v)
+	if err != nil {
+		panic(err)
+	}
+	switch schema.Type() {
+	case Union, Raw, Null:
+		return
+	}
+	typ := reflect2.TypeOf(v)
+	if _, isNamed := schema.(NamedSchema); !isNamed && isMarshalerType(typ) {
+		// Marshalers are bytes, which can not be told apart in a union
+		return
+	}
+	c.registered.add(typ, schema)
+	c.registerNamedType(typ, v, schema)
+}
+
+// registerNamedType registers both the value and the pointer type of a named schema so either can be encoded,
+// the given one last so it is the one built when decoding.
+func (c *frozenConfig) registerNamedType(typ reflect2.Type, v any, schema Schema) {
+	named, isNamed := schema.(NamedSchema)
+	if !isNamed {
+		return
+	}
+	if typ.Kind() == reflect.Ptr {
+		elemType := typ.(reflect2.PtrType).Elem()
+		c.Register(named.FullName(), elemType.Indirect(elemType.New()))
+	} else {
+		c.Register(named.FullName(), typ.New())
+	}
+	c.Register(named.FullName(), v)
+}
+
+// RegisterInterface registers the types of the union of the interface with the default config.
+func RegisterInterface(iface any, types ...any) {
+	DefaultConfig.RegisterInterface(iface, types...)
+}
+
+// RegisterInterface registers the types making up the union of fields of the interface, given as a nil pointer
+// to it: RegisterInterface((*Payload)(nil), &Created{}, Deleted{}). The union is null followed by the types,
+// in the given order, and values decode to the given types, pointers or not.
+//
+// Register interfaces before the types that use them, as derived schemas are cached.
+func (c *frozenConfig) RegisterInterface(iface any, types ...any) {
+	ptrType := reflect2.TypeOf(iface)
+	if ptrType == nil || ptrType.Kind() != reflect.Ptr || ptrType.(reflect2.PtrType).Elem().Kind() != reflect.Interface {
+		panic(fmt.Errorf("avro: RegisterInterface expects a pointer to an interface, got %T", iface))
+	}
+	ifaceType := ptrType.(reflect2.PtrType).Elem()
+
+	values := make([]registeredType, 0, len(types))
+	for _, v := range types {
+		typ := reflect2.TypeOf(v)
+		if typ == nil || !implementsInterface(typ, ifaceType) {
+			panic(fmt.Errorf("avro: %T does not implement %s", v, ifaceType.String()))
+		}
+		schema, err := c.ParseValue(v)
+		if err != nil {
+			panic(err)
+		}
+		switch schema.Type() {
+		case Union, Raw, Null:
+			panic(fmt.Errorf("avro: %s of %T can not be a branch of the union of %s", schema.Type(), v, ifaceType.String()))
+		}
+		if _, isNamed := schema.(NamedSchema); !isNamed && isMarshalerType(typ) {
+			// Marshalers are bytes, which can not be told apart in a union
+			panic(fmt.Errorf("avro: marshaler %T can not be a branch of the union of %s", v, ifaceType.String()))
+		}
+		for _, value := range values {
+			if schemaTypeName(value.schema) == schemaTypeName(schema) {
+				panic(fmt.Errorf("avro: %T and %s are the same branch of the union of %s", v, value.typ.String(), ifaceType.String()))
+			}
+		}
+		values = append(values, registeredType{typ: typ, schema: schema})
+		c.registerNamedType(typ, v, schema)
+	}
+	c.interfaces.Store(ifaceType.RType(), values)
+}
+
+// parseInterfaceType returns the union of null and the types registered for the interface with the config,
+// or else the types registered by RegisterSchemaByValue that implement it.
+func parseInterfaceType(cfg *frozenConfig, typ reflect2.Type) (s Schema, err error) {
+	types := Schemas{&NullSchema{}}
+	if registered, ok := cfg.interfaces.Load(typ.RType()); ok {
+		for _, value := range registered.([]registeredType) {
+			types = append(types, value.schema)
+		}
+	} else {
+		for _, value := range cfg.registered.list() {
+			if !implementsInterface(value.typ, typ) {
+				continue
+			}
+			if _, idx := types.Get(schemaTypeName(value.schema)); idx >= 0 {
+				continue
+			}
+			types = append(types, value.schema)
+		}
+	}
+	if len(types) == 1 {
+		err = fmt.Errorf("avro: interface %s has no registered types, see Register and RegisterInterface", typ.String())
+		return
+	}
+	s, err = NewUnionSchema(types)
+	return
+}
+
+func implementsInterface(typ reflect2.Type, iface reflect2.Type) bool {
+	if iface.Type1().NumMethod() == 0 {
+		return true
+	}
+	if typ.Implements(iface) {
+		return true
+	}
+	return typ.Kind() != reflect.Ptr && reflect2.PtrTo(typ).Implements(iface)
+}
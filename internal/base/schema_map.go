@@ -1,18 +1,24 @@
 package base
 
 import (
-	"fmt"
+	"errors"
 	"github.com/modern-go/reflect2"
 	"reflect"
 )
 
-func parseMapType(typ reflect2.Type) (s Schema, err error) {
+var errMapKey = errors.New("key of map must be string, integer or encoding.TextMarshaler, or the map must be derived as entries, see Config.MapEntries")
+
+func parseMapType(cfg *frozenConfig, typ reflect2.Type) (s Schema, err error) {
+	if cfg.config.MapEntries {
+		name, namespace := schemaNameOf(typ)
+		return parseMapEntriesType(cfg, typ, name+"_entry", namespace)
+	}
 	mapType := typ.(reflect2.MapType)
-	if mapType.Key().Kind() != reflect.String {
-		err = fmt.Errorf("key of map must be string")
+	if !isMapKeyType(mapType.Key()) {
+		err = errMapKey
 		return
 	}
-	elemSchema, elemErr := parseValueType(mapType.Elem())
+	elemSchema, elemErr := parseValueType(cfg, mapType.Elem())
 	if elemErr != nil {
 		err = elemErr
 		return
@@ -20,3 +26,66 @@ func parseMapType(typ reflect2.Type) (s Schema, err error) {
 	s = NewMapSchema(elemSchema)
 	return
 }
+
+// parseMapEntriesType returns the schema of the map as an array of records of its keys and values.
+func parseMapEntriesType(cfg *frozenConfig, typ reflect2.Type, name, namespace string) (s Schema, err error) {
+	mapType := typ.(reflect2.MapType)
+	keySchema, keyErr := parseValueType(cfg, mapType.Key())
+	if keyErr != nil {
+		err = keyErr
+		return
+	}
+	elemSchema, elemErr := parseValueType(cfg, mapType.Elem())
+	if elemErr != nil {
+		err = elemErr
+		return
+	}
+	key, keyErr := NewField("key", keySchema)
+	if keyErr != nil {
+		err = keyErr
+		return
+	}
+	value, valueErr := NewField("value", elemSchema)
+	if valueErr != nil {
+		err = valueErr
+		return
+	}
+	entry, entryErr := NewRecordSchema(name, namespace, []*Field{key, value})
+	if entryErr != nil {
+		err = entryErr
+		return
+	}
+	s = NewArraySchema(entry)
+	return
+}
+
+// isMapKeyType determines if map keys of the type are encoded as Avro map keys,
+// which are strings, integers and text marshalers.
+func isMapKeyType(typ reflect2.Type) bool {
+	switch typ.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return typ.Implements(textMarshalerType) &&
+		(typ.Implements(textUnmarshalerType) || reflect2.PtrTo(typ).Implements(textUnmarshalerType))
+}
+
+// isMapEntriesSchema determines if the schema is an array of key and value records.
+func isMapEntriesSchema(schema Schema) bool {
+	arr, ok := schema.(*ArraySchema)
+	if !ok {
+		return false
+	}
+	items := arr.Items()
+	if ref, isRef := items.(*RefSchema); isRef {
+		items = ref.Schema()
+	}
+	rec, ok := items.(*RecordSchema)
+	if !ok || len(rec.Fields()) != 2 {
+		return false
+	}
+	return rec.Fields()[0].Name() == "key" && rec.Fields()[1].Name() == "value"
+}
@@ -0,0 +1,63 @@
+package base
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/modern-go/reflect2"
+)
+
+// SchemaProvider is implemented by types declaring their own schema.
+//
+// The types are encoded by their Marshaler, or else by the codec of the declared schema.
+type SchemaProvider interface {
+	AvroSchema() string
+}
+
+var schemaProviderType = reflect2.TypeOfPtr((*SchemaProvider)(nil)).Elem()
+
+// GoTypeProp is the schema property recording the Go type of Marshaler types encoded as bytes.
+const GoTypeProp = "go.type"
+
+// parseMarshalerType returns the schema of types declaring their schema or implementing Marshaler,
+// or nil if the type does neither.
+func parseMarshalerType(typ reflect2.Type) (Schema, error) {
+	if provider := schemaProviderOf(typ); provider != nil {
+		s, err := ParseWithCache(provider.AvroSchema(), "", &SchemaCache{})
+		if err != nil {
+			return nil, fmt.Errorf("avro: parse schema of %s failed, %v", typ.String(), err)
+		}
+		return s, nil
+	}
+	if !isMarshalerType(typ) {
+		return nil, nil
+	}
+	name := typ.String()
+	if typ.Type1().PkgPath() != "" {
+		name = typ.Type1().PkgPath() + "." + typ.Type1().Name()
+	}
+	return NewPrimitiveSchema(Bytes, nil, WithProps(map[string]any{GoTypeProp: name})), nil
+}
+
+// isMarshalerType determines if the type declares its schema or implements Marshaler or Unmarshaler.
+func isMarshalerType(typ reflect2.Type) bool {
+	ptrType := reflect2.PtrTo(typ)
+	return typ.Implements(marshalerType) || typ.Implements(unmarshalerType) ||
+		ptrType.Implements(unmarshalerType) ||
+		typ.Implements(schemaProviderType) || ptrType.Implements(schemaProviderType)
+}
+
+func schemaProviderOf(typ reflect2.Type) SchemaProvider {
+	rtyp := typ.Type1()
+	if rtyp.Kind() == reflect.Ptr {
+		rtyp = rtyp.Elem()
+	}
+	v := reflect.New(rtyp)
+	if provider, ok := v.Interface().(SchemaProvider); ok {
+		return provider
+	}
+	if provider, ok := v.Elem().Interface().(SchemaProvider); ok {
+		return provider
+	}
+	return nil
+}
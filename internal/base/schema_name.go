@@ -0,0 +1,67 @@
+package base
+
+import (
+	"hash/fnv"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/modern-go/reflect2"
+)
+
+// AvroNamer is implemented by types overriding the name and namespace of their derived schema,
+// such as types whose names collide.
+type AvroNamer interface {
+	AvroName() (name, namespace string)
+}
+
+var avroNamerType = reflect.TypeOf((*AvroNamer)(nil)).Elem()
+
+// schemaNameOf returns the name and namespace of the derived schema of the type.
+//
+// Instantiated generic types are named after the import paths and names of their type arguments, such as
+// Page_github_com_acme_example_User for Page[github.com/acme/example.User], and anonymous structs after the hash
+// of their fields, such as Anonymous_1f0c3a5e9b7d2c4a.
+func schemaNameOf(typ reflect2.Type) (name, ns string) {
+	rtyp := typ.Type1()
+	if rtyp.Kind() != reflect.Interface && rtyp.Implements(avroNamerType) {
+		return reflect.New(rtyp).Elem().Interface().(AvroNamer).AvroName()
+	}
+	if reflect.PointerTo(rtyp).Implements(avroNamerType) {
+		return reflect.New(rtyp).Interface().(AvroNamer).AvroName()
+	}
+	if rtyp.Name() == "" {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(rtyp.String()))
+		return "Anonymous_" + strconv.FormatUint(h.Sum64(), 16), ""
+	}
+	return validTypeName(rtyp.Name()), namespace(rtyp.PkgPath())
+}
+
+// fullSchemaNameOf returns the namespace and name of the derived schema of the type, which is its cache key.
+func fullSchemaNameOf(typ reflect2.Type) string {
+	name, ns := schemaNameOf(typ)
+	return ns + "." + name
+}
+
+// validTypeName replaces the runs of characters of the Go type name that are invalid in Avro names with an underscore,
+// as the names of instantiated generic types contain their type arguments.
+func validTypeName(s string) string {
+	if !strings.ContainsAny(s, "[]") {
+		return s
+	}
+	b := strings.Builder{}
+	underscore := false
+	for _, c := range s {
+		if c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteRune(c)
+			underscore = false
+			continue
+		}
+		if !underscore {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.Trim(b.String(), "_")
+}
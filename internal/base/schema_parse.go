@@ -185,7 +185,11 @@ func parsePrimitiveLogicalType(typ Type, lt string, prec, scale int) LogicalSche
 		(typ == Int && ltyp == TimeMillis) ||
 		(typ == Long && ltyp == TimeMicros) ||
 		(typ == Long && ltyp == TimestampMillis) ||
-		(typ == Long && ltyp == TimestampMicros) {
+		(typ == Long && ltyp == TimestampMicros) ||
+		(typ == Long && ltyp == TimestampNanos) ||
+		(typ == Long && ltyp == LocalTimestampMillis) ||
+		(typ == Long && ltyp == LocalTimestampMicros) ||
+		(typ == Long && ltyp == LocalTimestampNanos) {
 		return NewPrimitiveLogicalSchema(ltyp)
 	}
 
@@ -481,6 +485,8 @@ func parseFixedLogicalType(size int, lt string, prec, scale int) LogicalSchema {
 	switch {
 	case ltyp == Duration && size == 12:
 		return NewPrimitiveLogicalSchema(Duration)
+	case ltyp == UUID && size == 16:
+		return NewPrimitiveLogicalSchema(UUID)
 	case ltyp == Decimal:
 		return parseDecimalLogicalType(size, prec, scale)
 	}
@@ -6,21 +6,37 @@ import (
 	"reflect"
 )
 
-func parsePtrType(typ reflect2.Type) (s Schema, err error) {
-	if typ.Implements(marshalerType) || typ.Implements(unmarshalerType) {
-		return NewPrimitiveSchema(Raw, nil), nil
+// parsePtrType returns the nullable union of the schema of the pointed type, unless the pointer
+// is a marshaler of its own.
+func parsePtrType(cfg *frozenConfig, typ reflect2.Type) (s Schema, err error) {
+	if isPtrMarshal(typ) {
+		return tryParseMarshal(typ)
 	}
 	ptrType := typ.(reflect2.PtrType)
-	elemType := ptrType.Elem()
-	if elemType.Kind() != reflect.Struct {
-		err = fmt.Errorf("avro: parse %s failed, only support ptr struct", typ.String())
-		return
-	}
-	elem, elemErr := parseStructType(elemType)
+	elem, elemErr := parsePtrElemType(cfg, ptrType.Elem())
 	if elemErr != nil {
 		err = elemErr
 		return
 	}
+	if elem.Type() == Union {
+		err = fmt.Errorf("avro: parse %s failed, ptr of nullable type is unsupported", typ.String())
+		return
+	}
 	s, err = NewUnionSchema([]Schema{&NullSchema{}, elem})
 	return
 }
+
+// isPtrMarshal determines if the pointer is encoded by its marshaler instead of as a nullable union,
+// which are the Avro marshalers and big.Rat.
+func isPtrMarshal(typ reflect2.Type) bool {
+	return isMarshalerType(typ) || typ.(reflect2.PtrType).Elem().Type1().ConvertibleTo(ratType)
+}
+
+// parsePtrElemType returns the schema of the pointed type. Times are timestamps
+// rather than the text of their marshaler.
+func parsePtrElemType(cfg *frozenConfig, typ reflect2.Type) (Schema, error) {
+	if typ.Kind() == reflect.Struct && typ.Type1().ConvertibleTo(timeType) {
+		return parseStructType(cfg, typ)
+	}
+	return parseValueType(cfg, typ)
+}
@@ -5,13 +5,13 @@ import (
 	"reflect"
 )
 
-func parseSliceType(typ reflect2.Type) (s Schema, err error) {
+func parseSliceType(cfg *frozenConfig, typ reflect2.Type) (s Schema, err error) {
 	elemType := typ.(reflect2.SliceType).Elem()
 	if elemType.Kind() == reflect.Uint8 {
 		s = NewPrimitiveSchema(Bytes, nil)
 		return
 	}
-	elemSchema, elemErr := parseValueType(elemType)
+	elemSchema, elemErr := parseValueType(cfg, elemType)
 	if elemErr != nil {
 		err = elemErr
 		return
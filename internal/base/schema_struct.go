@@ -4,25 +4,22 @@ import (
 	"fmt"
 	"github.com/modern-go/reflect2"
 	"reflect"
-	"strings"
 )
 
 const (
 	tag = "avro"
 )
 
-func parseStructType(typ reflect2.Type) (s Schema, err error) {
+func parseStructType(cfg *frozenConfig, typ reflect2.Type) (s Schema, err error) {
 	if typ.Type1().ConvertibleTo(timeType) {
-		return NewPrimitiveSchema(Long, NewPrimitiveLogicalSchema(TimestampMicros)), nil
+		return parseTimeType(cfg), nil
 	}
-	if typ.Implements(marshalerType) || typ.Implements(unmarshalerType) {
-		return NewPrimitiveSchema(Raw, nil), nil
+	if isMarshalerType(typ) {
+		return parseMarshalerType(typ)
 	}
-	pkg := typ.Type1().PkgPath()
-	pkg = namespace(pkg)
-	typeName := typ.Type1().Name()
+	typeName, pkg := schemaNameOf(typ)
 	processingKey := pkg + "." + typeName
-	s = DefaultSchemaCache.getProcessing(processingKey)
+	s = cfg.schemas.getProcessing(processingKey)
 	if s != nil {
 		return
 	}
@@ -31,9 +28,9 @@ func parseStructType(typ reflect2.Type) (s Schema, err error) {
 		err = rsErr
 		return
 	}
-	DefaultSchemaCache.addProcessing(processingKey, rs)
+	cfg.schemas.addProcessing(processingKey, rs)
 
-	fields, fieldsErr := parseStructFieldTypes(typ)
+	fields, fieldsErr := parseStructFieldTypes(cfg, typ)
 	if fieldsErr != nil {
 		err = fieldsErr
 		return
@@ -43,7 +40,7 @@ func parseStructType(typ reflect2.Type) (s Schema, err error) {
 	return
 }
 
-func parseStructFieldTypes(typ reflect2.Type) (fields []*Field, err error) {
+func parseStructFieldTypes(cfg *frozenConfig, typ reflect2.Type) (fields []*Field, err error) {
 	st := typ.(reflect2.StructType)
 	num := st.NumField()
 	for i := 0; i < num; i++ {
@@ -52,7 +49,7 @@ func parseStructFieldTypes(typ reflect2.Type) (fields []*Field, err error) {
 			if ft.Type().Kind() == reflect.Ptr && !ft.IsExported() {
 				continue
 			}
-			sub, subErr := parseStructFieldTypes(ft.Type())
+			sub, subErr := parseStructFieldTypes(cfg, ft.Type())
 			if subErr != nil {
 				err = subErr
 				return
@@ -63,166 +60,242 @@ func parseStructFieldTypes(typ reflect2.Type) (fields []*Field, err error) {
 		if !ft.IsExported() {
 			continue
 		}
-		pname := strings.TrimSpace(ft.Tag().Get(tag))
+		opts, optsErr := parseFieldTag(ft.Tag().Get(tag))
+		if optsErr != nil {
+			err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), optsErr)
+			return
+		}
+		pname := opts.name
 		if pname == "-" {
 			continue
 		}
 		if pname == "" {
 			pname = ft.Name()
 		}
-		var field *Field
-		var fieldErr error
-		switch ft.Type().Kind() {
-		case reflect.String:
-			field, fieldErr = NewField(pname, NewPrimitiveSchema(String, nil))
-			break
-		case reflect.Bool:
-			field, fieldErr = NewField(pname, NewPrimitiveSchema(Boolean, nil))
-			break
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
-			field, fieldErr = NewField(pname, NewPrimitiveSchema(Int, nil))
-			break
-		case reflect.Int64:
-			var fs Schema
-			if typ.Type1().ConvertibleTo(timeType) {
-				fs = NewPrimitiveSchema(Long, NewPrimitiveLogicalSchema(Duration))
-			} else {
-				fs = NewPrimitiveSchema(Long, nil)
-			}
-			field, fieldErr = NewField(pname, fs)
-			break
-		case reflect.Uint32:
-			field, fieldErr = NewField(pname, NewPrimitiveSchema(Long, nil))
-			break
-		case reflect.Float32:
-			field, fieldErr = NewField(pname, NewPrimitiveSchema(Float, nil))
-			break
-		case reflect.Float64:
-			field, fieldErr = NewField(pname, NewPrimitiveSchema(Double, nil))
-			break
-		case reflect.Uint, reflect.Uint64:
-			fs, fsErr := NewFixedSchema("uint", "", 8, NewPrimitiveLogicalSchema(Decimal))
+		fs, fsErr := opts.schema(typ, pname)
+		if fsErr != nil {
+			err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fsErr)
+			return
+		}
+		overridden := fs != nil
+		if !overridden {
+			fs = parseRegisteredType(cfg, ft.Type())
+		}
+		if fs == nil && isEnumType(ft.Type()) {
+			fs, fsErr = parseEnumType(ft.Type())
 			if fsErr != nil {
 				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fsErr)
 				return
 			}
-			field, fieldErr = NewField(pname, fs)
-			break
-		case reflect.Struct:
-			if typ.RType() == ft.Type().RType() {
-				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fmt.Errorf("please use ptr"))
+		}
+		if fs == nil && isMarshalerType(ft.Type()) {
+			fs, fsErr = parseMarshalerType(ft.Type())
+			if fsErr != nil {
+				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fsErr)
 				return
 			}
-			ms := tryParseMarshal(ft.Type())
-			if ms != nil {
-				field, fieldErr = NewField(pname, ms)
+		}
+		var field *Field
+		var fieldErr error
+		if fs != nil {
+			field, fieldErr = NewField(pname, fs)
+		} else {
+			switch ft.Type().Kind() {
+			case reflect.String:
+				field, fieldErr = NewField(pname, NewPrimitiveSchema(String, nil))
 				break
-			}
-			pkey := makeSchemaName(ft.Type())
-			if pkey == "" {
-				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fmt.Errorf("unsupported type"))
-				return
-			}
-
-			processing := DefaultSchemaCache.getProcessing(pkey)
-			if processing != nil {
-				named, isName := processing.(NamedSchema)
-				if !isName {
-					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fmt.Errorf("unsupported type"))
+			case reflect.Bool:
+				field, fieldErr = NewField(pname, NewPrimitiveSchema(Boolean, nil))
+				break
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+				field, fieldErr = NewField(pname, NewPrimitiveSchema(Int, nil))
+				break
+			case reflect.Int64:
+				if isDurationType(ft.Type().Type1()) {
+					field, fieldErr = NewField(pname, parseDurationType())
+					break
+				}
+				field, fieldErr = NewField(pname, NewPrimitiveSchema(Long, nil))
+				break
+			case reflect.Uint32:
+				field, fieldErr = NewField(pname, NewPrimitiveSchema(Long, nil))
+				break
+			case reflect.Float32:
+				field, fieldErr = NewField(pname, NewPrimitiveSchema(Float, nil))
+				break
+			case reflect.Float64:
+				field, fieldErr = NewField(pname, NewPrimitiveSchema(Double, nil))
+				break
+			case reflect.Uint, reflect.Uint64:
+				fs, fsErr := parseUintType(cfg)
+				if fsErr != nil {
+					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fsErr)
 					return
 				}
-				field, fieldErr = NewField(pname, NewRefSchema(named))
+				field, fieldErr = NewField(pname, fs)
 				break
-			}
-			processing, err = parseValueType(ft.Type())
-			if err != nil {
-				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), err)
-				return
-			}
-			field, fieldErr = NewField(pname, processing)
-			break
-		case reflect.Ptr:
-			ptrType := ft.Type().(reflect2.PtrType)
-			ms := tryParseMarshal(ptrType)
-			if ms != nil {
-				field, fieldErr = NewField(pname, ms)
+			case reflect.Struct:
+				if typ.RType() == ft.Type().RType() {
+					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fmt.Errorf("please use ptr"))
+					return
+				}
+				if ft.Type().Type1().ConvertibleTo(timeType) {
+					field, fieldErr = NewField(pname, parseTimeType(cfg))
+					break
+				}
+				ms, msErr := tryParseMarshal(ft.Type())
+				if msErr != nil {
+					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), msErr)
+					return
+				}
+				if ms != nil {
+					field, fieldErr = NewField(pname, ms)
+					break
+				}
+				pkey := makeSchemaName(cfg, ft.Type())
+				if pkey == "" {
+					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fmt.Errorf("unsupported type"))
+					return
+				}
+
+				processing := cfg.schemas.getProcessing(pkey)
+				if processing != nil {
+					named, isName := processing.(NamedSchema)
+					if !isName {
+						err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fmt.Errorf("unsupported type"))
+						return
+					}
+					field, fieldErr = NewField(pname, NewRefSchema(named))
+					break
+				}
+				processing, err = parseValueType(cfg, ft.Type())
+				if err != nil {
+					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), err)
+					return
+				}
+				field, fieldErr = NewField(pname, processing)
 				break
-			}
-			elemType := ptrType.Elem()
-			if elemType.Kind() != reflect.Struct {
-				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fmt.Errorf("unsupported type"))
-				return
-			}
-			pkey := makeSchemaName(elemType)
-			if pkey == "" {
-				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fmt.Errorf("unsupported type"))
-				return
-			}
-			processing := DefaultSchemaCache.getProcessing(pkey)
-			if processing != nil {
-				named, isName := processing.(NamedSchema)
-				if !isName {
+			case reflect.Ptr:
+				ptrType := ft.Type().(reflect2.PtrType)
+				elemType := ptrType.Elem()
+				if elemType.Kind() != reflect.Struct || isPtrMarshal(ptrType) {
+					fs, fsErr := parsePtrType(cfg, ptrType)
+					if fsErr != nil {
+						err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fsErr)
+						return
+					}
+					if fs.Type() == Union {
+						field, fieldErr = NewField(pname, fs, WithDefault(nil))
+						break
+					}
+					field, fieldErr = NewField(pname, fs)
+					break
+				}
+				pkey := makeSchemaName(cfg, elemType)
+				if pkey == "" {
 					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fmt.Errorf("unsupported type"))
 					return
 				}
-				union, unionErr := NewUnionSchema([]Schema{&NullSchema{}, NewRefSchema(named)})
+				processing := cfg.schemas.getProcessing(pkey)
+				if processing != nil {
+					named, isName := processing.(NamedSchema)
+					if !isName {
+						err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fmt.Errorf("unsupported type"))
+						return
+					}
+					union, unionErr := NewUnionSchema([]Schema{&NullSchema{}, NewRefSchema(named)})
+					if unionErr != nil {
+						err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), unionErr)
+						return
+					}
+					field, fieldErr = NewField(pname, union, WithDefault(nil))
+					break
+				}
+				processing, err = parsePtrElemType(cfg, elemType)
+				if err != nil {
+					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), err)
+					return
+				}
+				union, unionErr := NewUnionSchema([]Schema{&NullSchema{}, processing})
 				if unionErr != nil {
 					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), unionErr)
 					return
 				}
 				field, fieldErr = NewField(pname, union, WithDefault(nil))
 				break
-			}
-			processing, err = parseValueType(elemType)
-			if err != nil {
-				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), err)
-				return
-			}
-			union, unionErr := NewUnionSchema([]Schema{&NullSchema{}, processing})
-			if unionErr != nil {
-				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), unionErr)
-				return
-			}
-			field, fieldErr = NewField(pname, union, WithDefault(nil))
-			break
-		case reflect.Slice:
-			fs, fsErr := parseValueType(ft.Type())
-			if fsErr != nil {
-				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fsErr)
-				return
-			}
-			field, fieldErr = NewField(pname, fs)
-			break
-		case reflect.Array:
-			fs, fsErr := parseValueType(ft.Type())
-			if fsErr != nil {
-				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fsErr)
-				return
-			}
-			field, fieldErr = NewField(pname, fs)
-			break
-		case reflect.Map:
-			fs, fsErr := parseValueType(ft.Type())
-			if fsErr != nil {
-				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fsErr)
-				return
-			}
-			field, fieldErr = NewField(pname, fs)
-			break
-		default:
-			if ft.Type().Implements(marshalerType) || ft.Type().Implements(unmarshalerType) {
-				field, fieldErr = NewField(pname, rawSchema)
+			case reflect.Slice:
+				fs, fsErr := parseValueType(cfg, ft.Type())
+				if fsErr != nil {
+					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fsErr)
+					return
+				}
+				field, fieldErr = NewField(pname, fs)
 				break
-			}
-			pft := reflect2.PtrTo(ft.Type())
-			if pft.Implements(marshalerType) || pft.Implements(unmarshalerType) {
-				field, fieldErr = NewField(pname, rawSchema)
+			case reflect.Array:
+				var fs Schema
+				var fsErr error
+				if isFixedArrayType(ft.Type()) {
+					// Fixed schemas are named after the record and the field
+					structName, structNamespace := schemaNameOf(typ)
+					fs, fsErr = NewFixedSchema(structName+"_"+pname, structNamespace, ft.Type().(reflect2.ArrayType).Len(), nil)
+				} else {
+					fs, fsErr = parseValueType(cfg, ft.Type())
+				}
+				if fsErr != nil {
+					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fsErr)
+					return
+				}
+				field, fieldErr = NewField(pname, fs)
 				break
+			case reflect.Map:
+				var fs Schema
+				var fsErr error
+				if opts.entries || cfg.config.MapEntries {
+					structName, structNamespace := schemaNameOf(typ)
+					fs, fsErr = parseMapEntriesType(cfg, ft.Type(), structName+"_"+pname+"_entry", structNamespace)
+				} else if !isMapKeyType(ft.Type().(reflect2.MapType).Key()) {
+					fsErr = errMapKey
+				} else {
+					fs, fsErr = parseValueType(cfg, ft.Type())
+				}
+				if fsErr != nil {
+					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fsErr)
+					return
+				}
+				field, fieldErr = NewField(pname, fs)
+				break
+			case reflect.Interface:
+				fs, fsErr := parseValueType(cfg, ft.Type())
+				if fsErr != nil {
+					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fsErr)
+					return
+				}
+				if fs.Type() == Union {
+					field, fieldErr = NewField(pname, fs, WithDefault(nil))
+					break
+				}
+				field, fieldErr = NewField(pname, fs)
+				break
+			default:
+				ms, msErr := parseMarshalerType(ft.Type())
+				if msErr != nil {
+					err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), msErr)
+					return
+				}
+				if ms != nil {
+					field, fieldErr = NewField(pname, ms)
+					break
+				}
+				err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fmt.Errorf("unsupported type"))
+				return
 			}
-			err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fmt.Errorf("unsupported type"))
-			return
 		}
 
+		if fieldErr != nil {
+			err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fieldErr)
+			return
+		}
+		field, fieldErr = opts.apply(ft.Type(), field, overridden)
 		if fieldErr != nil {
 			err = fmt.Errorf("avro: parse %s.%s failed, %v", st.String(), ft.Name(), fieldErr)
 			return
@@ -0,0 +1,230 @@
+package base
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/modern-go/reflect2"
+)
+
+// fieldTag is the parsed avro tag of a struct field, `avro:"name,option=value,..."`.
+//
+// The options are:
+//
+//	logical=timestamp-millis  the logical type of the field
+//	fixed=16                  encode the field as a fixed of the size
+//	decimal=18:4              encode the field as a decimal of the precision and scale
+//	default="foo"             the JSON default of the field, or the raw value if it is not JSON
+//	doc=...                   the doc of the field
+//	alias=old                 an alias of the field, it may be repeated
+//	omitempty                 make the field nullable, encoding empty values as null
+//	entries                   encode a map as an array of key and value records
+//	uuid                      encode the field as a uuid, the same as logical=uuid
+//
+// The values of doc and default may contain commas.
+type fieldTag struct {
+	name      string
+	logical   LogicalType
+	fixed     int
+	precision int
+	scale     int
+	def       any
+	doc       string
+	aliases   []string
+	omitEmpty bool
+	entries   bool
+}
+
+var fieldTagOptions = map[string]bool{
+	"logical": true,
+	"fixed":   true,
+	"decimal": true,
+	"default": true,
+	"doc":     true,
+	"alias":   true,
+}
+
+var fieldTagFlags = map[string]bool{
+	"omitempty": true,
+	"entries":   true,
+	"uuid":      true,
+}
+
+// splitTagName returns the name of the tag and whether it has the omitempty option.
+func splitTagName(s string) (name string, omitEmpty bool) {
+	name, opts, _ := strings.Cut(s, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if strings.TrimSpace(opt) == "omitempty" {
+			omitEmpty = true
+			break
+		}
+	}
+	return strings.TrimSpace(name), omitEmpty
+}
+
+func parseFieldTag(s string) (t fieldTag, err error) {
+	t.def = NoDefault
+	parts := strings.Split(s, ",")
+	t.name = strings.TrimSpace(parts[0])
+
+	// Join the parts that are not options to the value of the previous option
+	var opts [][2]string
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		switch {
+		case !ok && fieldTagFlags[key]:
+			opts = append(opts, [2]string{key, ""})
+		case ok && fieldTagOptions[key]:
+			opts = append(opts, [2]string{key, value})
+		case len(opts) > 0 && !fieldTagFlags[opts[len(opts)-1][0]]:
+			opts[len(opts)-1][1] += "," + part
+		default:
+			err = fmt.Errorf("avro: unknown tag option %q", part)
+			return
+		}
+	}
+
+	for _, opt := range opts {
+		key, value := opt[0], opt[1]
+		switch key {
+		case "omitempty":
+			t.omitEmpty = true
+		case "entries":
+			t.entries = true
+		case "uuid":
+			t.logical = UUID
+		case "logical":
+			t.logical = LogicalType(strings.TrimSpace(value))
+		case "fixed":
+			t.fixed, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || t.fixed <= 0 {
+				err = fmt.Errorf("avro: invalid fixed size %q", value)
+				return
+			}
+		case "decimal":
+			prec, scale, _ := strings.Cut(value, ":")
+			t.precision, err = strconv.Atoi(strings.TrimSpace(prec))
+			if err != nil || t.precision <= 0 {
+				err = fmt.Errorf("avro: invalid decimal precision %q", value)
+				return
+			}
+			if scale != "" {
+				t.scale, err = strconv.Atoi(strings.TrimSpace(scale))
+				if err != nil || t.scale < 0 || t.scale > t.precision {
+					err = fmt.Errorf("avro: invalid decimal scale %q", value)
+					return
+				}
+			}
+			t.logical = Decimal
+		case "default":
+			var def any
+			if jsoniter.UnmarshalFromString(value, &def) != nil {
+				def = value
+			}
+			t.def = def
+		case "doc":
+			t.doc = strings.TrimSpace(value)
+		case "alias":
+			t.aliases = append(t.aliases, strings.TrimSpace(value))
+		}
+	}
+	return
+}
+
+// schema returns the schema of the logical and fixed options, or nil if they are not set.
+func (t fieldTag) schema(structType reflect2.Type, field string) (Schema, error) {
+	if t.logical == "" && t.fixed == 0 {
+		return nil, nil
+	}
+
+	var logical LogicalSchema
+	switch t.logical {
+	case "":
+	case Decimal:
+		if t.precision == 0 {
+			return nil, fmt.Errorf("avro: decimal requires the decimal=precision:scale option")
+		}
+		if t.scale > t.precision {
+			return nil, fmt.Errorf("avro: decimal scale %d is greater than the precision %d", t.scale, t.precision)
+		}
+		logical = NewDecimalLogicalSchema(t.precision, t.scale)
+	case Date, TimeMillis:
+		return NewPrimitiveSchema(Int, NewPrimitiveLogicalSchema(t.logical)), nil
+	case TimeMicros, TimestampMillis, TimestampMicros, TimestampNanos,
+		LocalTimestampMillis, LocalTimestampMicros, LocalTimestampNanos:
+		return NewPrimitiveSchema(Long, NewPrimitiveLogicalSchema(t.logical)), nil
+	case UUID, Duration:
+		logical = NewPrimitiveLogicalSchema(t.logical)
+	default:
+		return nil, fmt.Errorf("avro: unknown logical type %q", t.logical)
+	}
+
+	size := t.fixed
+	if t.logical == Duration {
+		size = 12
+	}
+	if t.logical == UUID && size != 0 && size != 16 {
+		return nil, fmt.Errorf("avro: uuid requires a fixed size of 16")
+	}
+	if size == 0 {
+		if t.logical == UUID {
+			// UUIDs are strings, unless the fixed option is set
+			return NewPrimitiveSchema(String, logical), nil
+		}
+		return NewPrimitiveSchema(Bytes, logical), nil
+	}
+	if t.logical == Decimal {
+		maxPrecision := int(math.Round(math.Floor(math.Log10(2) * (8*float64(size) - 1))))
+		if t.precision > maxPrecision {
+			return nil, fmt.Errorf("avro: decimal precision %d does not fit a fixed size of %d", t.precision, size)
+		}
+	}
+
+	// Fixed schemas are named after the record and the field
+	name, ns := schemaNameOf(structType)
+	return NewFixedSchema(name+"_"+field, ns, size, logical)
+}
+
+// apply returns the field with the doc, alias, default and omitempty options of the tag applied.
+//
+// Pointers to other types than big.Rat are nullable when their schema is overridden by the tag.
+func (t fieldTag) apply(typ reflect2.Type, field *Field, overridden bool) (*Field, error) {
+	if !overridden && !t.omitEmpty && t.def == NoDefault && t.doc == "" && len(t.aliases) == 0 {
+		return field, nil
+	}
+
+	schema := field.Type()
+	def := t.def
+	if def == NoDefault && field.HasDefault() {
+		def = field.Default()
+	}
+	nullable := t.omitEmpty
+	if overridden && typ.Kind() == reflect.Ptr && !typ.(reflect2.PtrType).Elem().Type1().ConvertibleTo(ratType) {
+		nullable = true
+	}
+	if nullable && !(schema.Type() == Union && schema.(*UnionSchema).Nullable()) {
+		types := []Schema{&NullSchema{}, schema}
+		if def == NoDefault {
+			def = nil
+		} else if def != nil {
+			// The default of a union is of its first type
+			types[0], types[1] = types[1], types[0]
+		}
+		var err error
+		schema, err = NewUnionSchema(types)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	opts := []SchemaOption{WithAliases(t.aliases), WithDoc(t.doc)}
+	if def != NoDefault {
+		opts = append(opts, WithDefault(def))
+	}
+	return NewField(field.Name(), schema, opts...)
+}
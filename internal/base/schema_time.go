@@ -0,0 +1,46 @@
+package base
+
+import "reflect"
+
+// DurationProp is the schema property marking long schemas of time.Duration nanoseconds.
+const DurationProp = "go.duration"
+
+// TimePrecision is the precision of timestamps in schemas derived from time.Time.
+type TimePrecision int
+
+const (
+	// PrecisionMicros derives timestamp-micros.
+	PrecisionMicros TimePrecision = iota
+	// PrecisionMillis derives timestamp-millis.
+	PrecisionMillis
+	// PrecisionNanos derives timestamp-nanos.
+	PrecisionNanos
+)
+
+// timestampLogicalType returns the timestamp logical type of the precision of the config.
+func timestampLogicalType(cfg *frozenConfig) LogicalType {
+	switch cfg.config.TimePrecision {
+	case PrecisionMillis:
+		return TimestampMillis
+	case PrecisionNanos:
+		return TimestampNanos
+	default:
+		return TimestampMicros
+	}
+}
+
+// parseTimeType returns the timestamp schema of time.Time.
+func parseTimeType(cfg *frozenConfig) Schema {
+	return NewPrimitiveSchema(Long, NewPrimitiveLogicalSchema(timestampLogicalType(cfg)))
+}
+
+// isDurationType returns true if the type is time.Duration.
+func isDurationType(typ reflect.Type) bool {
+	return typ == timeDurationType
+}
+
+// parseDurationType returns the long schema of time.Duration nanoseconds, with the go.duration property.
+// The time-millis and time-micros tags derive the time logical types instead.
+func parseDurationType() Schema {
+	return NewPrimitiveSchema(Long, nil, WithProps(map[string]any{DurationProp: "nanos"}))
+}
@@ -4,37 +4,44 @@ import (
 	"fmt"
 	"github.com/modern-go/reflect2"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
+// RegisterSchemaByValue derives the schema of v with the default config and caches it.
+// The type of v is a branch of the unions of the interfaces it implements, see parseInterfaceType.
 func RegisterSchemaByValue(v any) {
-	_, err := ParseValue(v)
-	if err != nil {
-		panic(err)
-	}
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig.Load().registerValue(v)
 }
 
+// ParseValue derives the schema of v with the default config.
 func ParseValue(v any) (s Schema, err error) {
+	return DefaultConfig.ParseValue(v)
+}
+
+func (c *frozenConfig) ParseValue(v any) (s Schema, err error) {
 	typ := reflect2.TypeOf(v)
 	if typ.Kind() == reflect.Ptr {
 		typ = typ.(reflect2.PtrType).Elem()
 	}
-	key := makeSchemaName(typ)
+	key := makeSchemaName(c, typ)
 	if key == "" {
 		err = fmt.Errorf("avro: type %s is unsupported", typ.String())
 		return
 	}
-	s = DefaultSchemaCache.Get(key)
+	s = c.schemas.Get(key)
 	if s != nil {
 		return
 	}
-	r, doErr, _ := DefaultSchemaCache.processingGroup.Do(key, func() (r any, err error) {
-		parsed, parseErr := parseValueType(typ)
+	r, doErr, _ := c.schemas.processingGroup.Do(key, func() (r any, err error) {
+		parsed, parseErr := parseValueType(c, typ)
 		if parseErr != nil {
 			err = parseErr
 			return
 		}
-		DefaultSchemaCache.Add(key, parsed)
+		c.schemas.Add(key, parsed)
 		r = parsed
 		return
 	})
@@ -46,32 +53,42 @@ func ParseValue(v any) (s Schema, err error) {
 	return
 }
 
-func tryParseMarshal(typ reflect2.Type) (s Schema) {
-	if typ.Implements(marshalerType) || typ.Implements(unmarshalerType) {
-		return NewPrimitiveSchema(Raw, nil)
-	}
-	if reflect2.PtrTo(typ).Implements(unmarshalerType) {
-		return NewPrimitiveSchema(Raw, nil)
+func tryParseMarshal(typ reflect2.Type) (s Schema, err error) {
+	s, err = parseMarshalerType(typ)
+	if s != nil || err != nil {
+		return
 	}
 	if reflect2.PtrTo(typ).Implements(textMarshalerType) {
-		return NewPrimitiveSchema(String, nil)
+		return NewPrimitiveSchema(String, nil), nil
 	}
 	if typ.Implements(textMarshalerType) {
-		return NewPrimitiveSchema(String, nil)
+		return NewPrimitiveSchema(String, nil), nil
 	}
 	if reflect2.PtrTo(typ).Implements(textUnmarshalerType) {
-		return NewPrimitiveSchema(String, nil)
+		return NewPrimitiveSchema(String, nil), nil
 	}
 	if typ.Implements(textUnmarshalerType) {
-		return NewPrimitiveSchema(String, nil)
+		return NewPrimitiveSchema(String, nil), nil
 	}
-	return nil
+	return nil, nil
 }
 
-func parseValueType(typ reflect2.Type) (s Schema, err error) {
-	ms := tryParseMarshal(typ)
-	if ms != nil {
-		s = ms
+func parseValueType(cfg *frozenConfig, typ reflect2.Type) (s Schema, err error) {
+	if rs := parseRegisteredType(cfg, typ); rs != nil {
+		return rs, nil
+	}
+	if isEnumType(typ) {
+		return parseEnumType(typ)
+	}
+	if isUUIDType(typ) {
+		return parseUUIDType(typ)
+	}
+	if typ.Type1().ConvertibleTo(timeType) {
+		return parseTimeType(cfg), nil
+	}
+	ms, msErr := tryParseMarshal(typ)
+	if ms != nil || msErr != nil {
+		s, err = ms, msErr
 		return
 	}
 	switch typ.Kind() {
@@ -82,8 +99,8 @@ func parseValueType(typ reflect2.Type) (s Schema, err error) {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
 		return NewPrimitiveSchema(Int, nil), nil
 	case reflect.Int64:
-		if typ.Type1().ConvertibleTo(timeType) {
-			return NewPrimitiveSchema(Long, NewPrimitiveLogicalSchema(Duration)), nil
+		if isDurationType(typ.Type1()) {
+			return parseDurationType(), nil
 		}
 		return NewPrimitiveSchema(Long, nil), nil
 	case reflect.Uint32:
@@ -93,34 +110,33 @@ func parseValueType(typ reflect2.Type) (s Schema, err error) {
 	case reflect.Float64:
 		return NewPrimitiveSchema(Double, nil), nil
 	case reflect.Uint, reflect.Uint64:
-		return NewFixedSchema("uint", "", 8, NewPrimitiveLogicalSchema(Decimal))
+		return parseUintType(cfg)
 	case reflect.Struct:
-		return parseStructType(typ)
+		return parseStructType(cfg, typ)
 	case reflect.Ptr:
-		return parsePtrType(typ)
+		return parsePtrType(cfg, typ)
 	case reflect.Slice:
-		return parseSliceType(typ)
+		return parseSliceType(cfg, typ)
 	case reflect.Array:
-		return parseArrayType(typ)
+		return parseArrayType(cfg, typ)
 	case reflect.Map:
-		return parseMapType(typ)
+		return parseMapType(cfg, typ)
+	case reflect.Interface:
+		return parseInterfaceType(cfg, typ)
 	default:
-		if typ.Implements(marshalerType) || typ.Implements(unmarshalerType) {
-			return NewPrimitiveSchema(Raw, nil), nil
-		}
-		if reflect2.PtrTo(typ).Implements(unmarshalerType) {
-			return NewPrimitiveSchema(Raw, nil), nil
-		}
 		return nil, fmt.Errorf("avro: type %s is unsupported", typ.String())
 	}
 }
 
-func makeSchemaName(typ reflect2.Type) string {
-	if typ.Implements(marshalerType) || typ.Implements(unmarshalerType) {
-		return namespace(typ.Type1().PkgPath()) + "." + typ.Type1().Name()
+func makeSchemaName(cfg *frozenConfig, typ reflect2.Type) string {
+	if parseRegisteredType(cfg, typ) != nil {
+		if typ.Type1().Name() == "" {
+			return typ.String()
+		}
+		return fullSchemaNameOf(typ)
 	}
-	if reflect2.PtrTo(typ).Implements(unmarshalerType) {
-		return namespace(typ.Type1().PkgPath()) + "." + typ.Type1().Name()
+	if isMarshalerType(typ) || isUUIDType(typ) || isEnumType(typ) {
+		return fullSchemaNameOf(typ)
 	}
 	switch typ.Kind() {
 	case reflect.String:
@@ -130,8 +146,8 @@ func makeSchemaName(typ reflect2.Type) string {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
 		return string(Int)
 	case reflect.Int64:
-		if typ.Type1().PkgPath() == "time" && typ.Type1().Name() == "Duration" {
-			return string(Long) + "." + string(Duration)
+		if isDurationType(typ.Type1()) {
+			return string(Long) + "." + DurationProp
 		}
 		return string(Long)
 	case reflect.Uint32:
@@ -141,18 +157,14 @@ func makeSchemaName(typ reflect2.Type) string {
 	case reflect.Float64:
 		return string(Double)
 	case reflect.Uint, reflect.Uint64:
-		return string(Fixed) + "." + string(Decimal)
+		return UintProp
 	case reflect.Struct:
 		if typ.Type1().ConvertibleTo(timeType) {
-			return string(Long) + "." + string(TimestampMicros)
+			return string(Long) + "." + string(timestampLogicalType(cfg))
 		}
-		return namespace(typ.Type1().PkgPath()) + "." + typ.Type1().Name()
+		return fullSchemaNameOf(typ)
 	case reflect.Ptr:
-		elemType := typ.Type1().Elem()
-		if elemType.Kind() != reflect.Struct {
-			return ""
-		}
-		elem := makeSchemaName(reflect2.Type2(elemType))
+		elem := makeSchemaName(cfg, reflect2.Type2(typ.Type1().Elem()))
 		if elem == "" {
 			return ""
 		}
@@ -161,23 +173,33 @@ func makeSchemaName(typ reflect2.Type) string {
 		if typ.Type1().Elem().Kind() == reflect.Uint8 {
 			return string(Bytes)
 		}
-		elem := makeSchemaName(typ.(reflect2.SliceType).Elem())
+		elem := makeSchemaName(cfg, typ.(reflect2.SliceType).Elem())
 		if elem == "" {
 			return ""
 		}
 		return elem + "_slice"
 	case reflect.Array:
-		elem := makeSchemaName(typ.(reflect2.ArrayType).Elem())
+		arrayType := typ.(reflect2.ArrayType)
+		if arrayType.Elem().Kind() == reflect.Uint8 {
+			if typ.Type1().Name() == "" {
+				return "go.fixed" + strconv.Itoa(arrayType.Len())
+			}
+			return fullSchemaNameOf(typ)
+		}
+		elem := makeSchemaName(cfg, arrayType.Elem())
 		if elem == "" {
 			return ""
 		}
 		return elem + "_array"
 	case reflect.Map:
+		if cfg.config.MapEntries {
+			return fullSchemaNameOf(typ) + "_entries"
+		}
 		mapType := typ.(reflect2.MapType)
-		if mapType.Key().Kind() != reflect.String {
+		if !isMapKeyType(mapType.Key()) {
 			return ""
 		}
-		elem := makeSchemaName(mapType.Elem())
+		elem := makeSchemaName(cfg, mapType.Elem())
 		if elem == "" {
 			return ""
 		}
@@ -0,0 +1,143 @@
+package base
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// singleObjectMarker is the two byte marker of the Avro single object encoding.
+var singleObjectMarker = [2]byte{0xc3, 0x01}
+
+// singleObjectHeaderSize is the size of the marker and the CRC-64-AVRO fingerprint.
+const singleObjectHeaderSize = 10
+
+// SchemaStore looks up writer schemas by their CRC-64-AVRO fingerprint.
+type SchemaStore interface {
+	// Schema returns the schema with the given fingerprint or an error.
+	Schema(fingerprint uint64) (Schema, error)
+}
+
+// MemorySchemaStore is an in memory SchemaStore.
+type MemorySchemaStore struct {
+	schemas sync.Map // map[uint64]Schema
+}
+
+// NewSchemaStore creates a new in memory schema store holding schemas.
+func NewSchemaStore(schemas ...Schema) (*MemorySchemaStore, error) {
+	store := &MemorySchemaStore{}
+	for _, schema := range schemas {
+		if err := store.Add(schema); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// Add adds a schema to the store.
+func (s *MemorySchemaStore) Add(schema Schema) error {
+	fingerprint, err := SingleObjectFingerprint(schema)
+	if err != nil {
+		return err
+	}
+	s.schemas.Store(fingerprint, schema)
+	return nil
+}
+
+// Schema returns the schema with the given fingerprint or an error.
+func (s *MemorySchemaStore) Schema(fingerprint uint64) (Schema, error) {
+	schema, ok := s.schemas.Load(fingerprint)
+	if !ok {
+		return nil, fmt.Errorf("avro: unknown schema with fingerprint %x", fingerprint)
+	}
+	return schema.(Schema), nil
+}
+
+// SingleObjectFingerprint returns the CRC-64-AVRO fingerprint of the schema.
+func SingleObjectFingerprint(schema Schema) (uint64, error) {
+	fingerprint, err := schema.FingerprintUsing(CRC64Avro)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(fingerprint), nil
+}
+
+// SingleObjectHeader returns the fingerprint of the writer schema from the
+// single object encoded data, or an error if data is not single object encoded.
+func SingleObjectHeader(data []byte) (uint64, error) {
+	if len(data) < singleObjectHeaderSize || data[0] != singleObjectMarker[0] || data[1] != singleObjectMarker[1] {
+		return 0, errors.New("avro: invalid single object encoding header")
+	}
+	return binary.LittleEndian.Uint64(data[2:singleObjectHeaderSize]), nil
+}
+
+func (c *frozenConfig) MarshalSingleObject(schema Schema, v any) ([]byte, error) {
+	fingerprint, err := SingleObjectFingerprint(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := c.borrowWriter()
+
+	var header [singleObjectHeaderSize]byte
+	copy(header[:], singleObjectMarker[:])
+	binary.LittleEndian.PutUint64(header[2:], fingerprint)
+	_, _ = writer.Write(header[:])
+
+	writer.WriteVal(schema, v)
+	if err = writer.Error; err != nil {
+		c.returnWriter(writer)
+		return nil, err
+	}
+
+	result := writer.Buffer()
+	copied := make([]byte, len(result))
+	copy(copied, result)
+
+	c.returnWriter(writer)
+	return copied, nil
+}
+
+func (c *frozenConfig) UnmarshalSingleObject(store SchemaStore, schema Schema, data []byte, v any) error {
+	fingerprint, err := SingleObjectHeader(data)
+	if err != nil {
+		return err
+	}
+	data = data[singleObjectHeaderSize:]
+
+	if schema != nil {
+		readerFingerprint, fpErr := SingleObjectFingerprint(schema)
+		if fpErr != nil {
+			return fpErr
+		}
+		if readerFingerprint == fingerprint {
+			return c.Unmarshal(schema, data, v)
+		}
+	}
+
+	if store == nil {
+		return fmt.Errorf("avro: unknown schema with fingerprint %x", fingerprint)
+	}
+	writer, err := store.Schema(fingerprint)
+	if err != nil {
+		return err
+	}
+	if schema == nil {
+		return c.Unmarshal(writer, data, v)
+	}
+	return c.UnmarshalWithWriterSchema(schema, writer, data, v)
+}
+
+// MarshalSingleObject returns the Avro single object encoding of v, which is the
+// single object marker and the fingerprint of the schema followed by the Avro encoding of v.
+func MarshalSingleObject(schema Schema, v any) ([]byte, error) {
+	return DefaultConfig.MarshalSingleObject(schema, v)
+}
+
+// UnmarshalSingleObject parses the Avro single object encoded data and stores the result in the value pointed to by v.
+// The writer schema is looked up in the store by its fingerprint, unless it is the reader schema. If the
+// reader schema is nil, the writer schema is used to decode.
+func UnmarshalSingleObject(store SchemaStore, schema Schema, data []byte, v any) error {
+	return DefaultConfig.UnmarshalSingleObject(store, schema, data, v)
+}
@@ -12,7 +12,7 @@ type WriterFunc func(w *Writer)
 // WithWriterConfig specifies the configuration to use with a writer.
 func WithWriterConfig(cfg API) WriterFunc {
 	return func(w *Writer) {
-		w.cfg = cfg.(*frozenConfig)
+		w.cfg = frozenConfigOf(cfg)
 	}
 }
 
@@ -27,7 +27,7 @@ type Writer struct {
 // NewWriter creates a new Writer.
 func NewWriter(out io.Writer, bufSize int, opts ...WriterFunc) *Writer {
 	writer := &Writer{
-		cfg:   DefaultConfig.(*frozenConfig),
+		cfg:   defaultConfig.Load(),
 		out:   out,
 		buf:   make([]byte, 0, bufSize),
 		Error: nil,
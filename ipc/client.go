@@ -0,0 +1,390 @@
+package ipc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aacfactory/avro/internal/base"
+)
+
+// Transceiver sends requests to a server and receives its responses.
+type Transceiver interface {
+	// Transceive sends the request and returns its response. Stateful transceivers do not
+	// wait for the response of one-way requests, and return nil.
+	Transceive(ctx context.Context, req []byte, oneWay bool) ([]byte, error)
+
+	// Stateful determines if the server keeps the handshake of the transceiver for later requests.
+	Stateful() bool
+
+	// Close closes the transceiver.
+	Close() error
+}
+
+// ConnTransceiver is a stateful transceiver over a connection, which sends one request at a time.
+//
+// The connection is closed when a call fails or its context is done, as a late response would otherwise
+// be read as the response of the next call, and later calls return the error of the failed call.
+type ConnTransceiver struct {
+	mu   sync.Mutex
+	conn net.Conn
+	err  error
+}
+
+// NewConnTransceiver returns a transceiver over the connection.
+func NewConnTransceiver(conn net.Conn) *ConnTransceiver {
+	return &ConnTransceiver{conn: conn}
+}
+
+// Dial connects to the address on the network, and returns a transceiver over the connection.
+func Dial(ctx context.Context, network, address string) (*ConnTransceiver, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return NewConnTransceiver(conn), nil
+}
+
+// Transceive sends the request and returns its response.
+func (t *ConnTransceiver) Transceive(ctx context.Context, req []byte, oneWay bool) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.err != nil {
+		return nil, t.err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := t.conn.SetDeadline(deadline); err != nil {
+		return nil, t.fail(ctx, err)
+	}
+	// Unblock the reads and writes of the call when the context is done
+	interrupted := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		_ = t.conn.SetDeadline(time.Unix(1, 0))
+		close(interrupted)
+	})
+	defer func() {
+		if !stop() {
+			<-interrupted
+		}
+	}()
+
+	if err := writeFrames(t.conn, req); err != nil {
+		return nil, t.fail(ctx, err)
+	}
+	if oneWay {
+		return nil, nil
+	}
+	p, err := readFrames(t.conn, DefaultMaxMessageSize)
+	if err != nil {
+		return nil, t.fail(ctx, err)
+	}
+	return p, nil
+}
+
+// fail closes the connection and marks the transceiver as failed, returning the error of the call.
+func (t *ConnTransceiver) fail(ctx context.Context, err error) error {
+	_ = t.conn.Close()
+	if _, ok := ctx.Deadline(); ok && errors.Is(err, os.ErrDeadlineExceeded) {
+		// The connection has the deadline of the context, which is done at the same time
+		<-ctx.Done()
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+	t.err = fmt.Errorf("avro: transceiver failed, %w", err)
+	return err
+}
+
+// Stateful returns true.
+func (t *ConnTransceiver) Stateful() bool {
+	return true
+}
+
+// Close closes the connection.
+func (t *ConnTransceiver) Close() error {
+	return t.conn.Close()
+}
+
+// HTTPTransceiver is a stateless transceiver posting requests to a URL.
+type HTTPTransceiver struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPTransceiver returns a transceiver posting requests to the URL with the client,
+// or with http.DefaultClient if it is nil.
+func NewHTTPTransceiver(url string, client *http.Client) *HTTPTransceiver {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPTransceiver{url: url, client: client}
+}
+
+// Transceive posts the request and returns its response.
+func (t *HTTPTransceiver) Transceive(ctx context.Context, req []byte, _ bool) ([]byte, error) {
+	body := bytes.NewBuffer(nil)
+	if err := writeFrames(body, req); err != nil {
+		return nil, err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, body)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", ContentType)
+	resp, err := t.client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("avro: http call failed, %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return readFrames(resp.Body, DefaultMaxMessageSize)
+}
+
+// Stateful returns false.
+func (t *HTTPTransceiver) Stateful() bool {
+	return false
+}
+
+// Close closes the idle connections of the client.
+func (t *HTTPTransceiver) Close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+// Client calls the messages of a protocol.
+type Client struct {
+	protocol    *base.Protocol
+	hash        [16]byte
+	text        string
+	transceiver Transceiver
+
+	handshakeMu sync.Mutex
+	mu          sync.Mutex
+	server      *base.Protocol
+	serverHash  [16]byte
+	handshaken  bool
+
+	errorTypes sync.Map // map[string]reflect.Type
+}
+
+// NewClient returns a client of the protocol, calling a server through the transceiver.
+func NewClient(protocol *base.Protocol, transceiver Transceiver) *Client {
+	hash := hashOf(protocol)
+	return &Client{
+		protocol:    protocol,
+		hash:        hash,
+		text:        protocol.Declaration(),
+		transceiver: transceiver,
+		server:      protocol,
+		serverHash:  hash,
+	}
+}
+
+// RegisterError registers the Go type of the declared error schema of the name, such as
+// org.example.NotFound. Errors of the schema are decoded to a new value of the type of v,
+// which is returned if it implements error, and wrapped in an *Error otherwise.
+func (c *Client) RegisterError(name string, v any) {
+	typ := reflect.TypeOf(v)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	c.errorTypes.Store(name, typ)
+}
+
+// Call calls the message with the request parameters, a struct or a map, and decodes its response into resp.
+// The resp is ignored by messages without response.
+func (c *Client) Call(ctx context.Context, message string, req any, resp any) error {
+	msg := c.protocol.Message(message)
+	if msg == nil {
+		return fmt.Errorf("avro: protocol %s has no message %s", c.protocol.FullName(), message)
+	}
+
+	if c.transceiver.Stateful() {
+		// Calls wait for the first call of stateful transceivers to handshake
+		c.handshakeMu.Lock()
+		if c.isHandshaken() {
+			c.handshakeMu.Unlock()
+		} else {
+			defer c.handshakeMu.Unlock()
+		}
+	}
+
+	sendProtocol := false
+	for {
+		withHandshake := !c.transceiver.Stateful() || !c.isHandshaken()
+		p, err := c.request(message, msg, req, withHandshake, sendProtocol)
+		if err != nil {
+			return err
+		}
+		oneWay := msg.OneWay() && !withHandshake
+		p, err = c.transceiver.Transceive(ctx, p, oneWay)
+		if err != nil {
+			return err
+		}
+		if oneWay {
+			return nil
+		}
+
+		r := newReader(p)
+		if withHandshake {
+			match, hsErr := c.readHandshake(r)
+			if hsErr != nil {
+				return hsErr
+			}
+			if match == MatchNone {
+				if sendProtocol {
+					return errors.New("avro: server does not accept the client protocol")
+				}
+				sendProtocol = true
+				continue
+			}
+		}
+		if msg.OneWay() {
+			return nil
+		}
+		return c.response(r, message, msg, resp)
+	}
+}
+
+func (c *Client) isHandshaken() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.handshaken
+}
+
+// readHandshake reads the handshake response, and keeps the protocol of the server it contains.
+func (c *Client) readHandshake(r *base.Reader) (string, error) {
+	hs := HandshakeResponse{}
+	r.ReadVal(HandshakeResponseSchema, &hs)
+	if r.Error != nil {
+		return "", fmt.Errorf("avro: read handshake failed, %w", r.Error)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hs.ServerProtocol != nil && hs.ServerHash != nil {
+		server, err := base.ParseProtocol(*hs.ServerProtocol)
+		if err != nil {
+			return "", fmt.Errorf("avro: parse server protocol failed, %w", err)
+		}
+		c.server, c.serverHash = server, *hs.ServerHash
+	}
+	if hs.Match != MatchNone {
+		c.handshaken = true
+	}
+	return hs.Match, nil
+}
+
+// request returns the request of the call, preceded by a handshake if it is set.
+func (c *Client) request(name string, msg *base.Message, req any, withHandshake, sendProtocol bool) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	w := newWriter(buf)
+	if withHandshake {
+		c.mu.Lock()
+		hs := HandshakeRequest{ClientHash: c.hash, ServerHash: c.serverHash}
+		c.mu.Unlock()
+		if sendProtocol {
+			hs.ClientProtocol = &c.text
+		}
+		w.WriteVal(HandshakeRequestSchema, hs)
+	}
+	w.WriteVal(metaSchema, map[string][]byte{})
+	w.WriteString(name)
+	if len(msg.Request().Fields()) > 0 {
+		w.WriteVal(msg.Request(), req)
+	}
+	if w.Error != nil {
+		return nil, fmt.Errorf("avro: write request of %s failed, %w", name, w.Error)
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// response decodes the response of the call into resp, or returns its error.
+func (c *Client) response(r *base.Reader, name string, msg *base.Message, resp any) error {
+	c.mu.Lock()
+	server := c.server.Message(name)
+	c.mu.Unlock()
+	if server == nil {
+		server = msg
+	}
+
+	var meta map[string][]byte
+	r.ReadVal(metaSchema, &meta)
+	failed := r.ReadBool()
+	if r.Error != nil {
+		return fmt.Errorf("avro: read response of %s failed, %w", name, r.Error)
+	}
+	if !failed {
+		if msg.Response() == nil || resp == nil {
+			return nil
+		}
+		if server.Response() == nil {
+			return fmt.Errorf("avro: server message %s has no response", name)
+		}
+		r.ReadValWithWriterSchema(msg.Response(), server.Response(), resp)
+		if r.Error != nil {
+			return fmt.Errorf("avro: read response of %s failed, %w", name, r.Error)
+		}
+		return nil
+	}
+
+	types := errorTypesOf(server)
+	i := int(r.ReadLong())
+	if r.Error != nil || i < 0 || i >= len(types) {
+		return fmt.Errorf("avro: read error of %s failed, invalid union index %d", name, i)
+	}
+	if i == 0 {
+		s := r.ReadString()
+		if r.Error != nil {
+			return fmt.Errorf("avro: read error of %s failed, %w", name, r.Error)
+		}
+		return &Error{Name: string(base.String), Value: s}
+	}
+
+	writer := types[i]
+	errName := nameOf(writer)
+	schema := writer
+	for _, declared := range errorTypesOf(msg)[1:] {
+		if nameOf(declared) == errName {
+			schema = declared
+		}
+	}
+	if typ, ok := c.errorTypes.Load(errName); ok {
+		v := reflect.New(typ.(reflect.Type))
+		r.ReadValWithWriterSchema(schema, writer, v.Interface())
+		if r.Error != nil {
+			return fmt.Errorf("avro: read error of %s failed, %w", name, r.Error)
+		}
+		if err, isErr := v.Interface().(error); isErr {
+			return err
+		}
+		if err, isErr := v.Elem().Interface().(error); isErr {
+			return err
+		}
+		return &Error{Name: errName, Value: v.Elem().Interface()}
+	}
+	value := r.ReadNext(writer)
+	if r.Error != nil {
+		return fmt.Errorf("avro: read error of %s failed, %w", name, r.Error)
+	}
+	return &Error{Name: errName, Value: value}
+}
@@ -0,0 +1,196 @@
+// Package ipc implements Avro RPC clients and servers of protocols, over connections and HTTP.
+//
+// See the Avro specification for an understanding of Avro RPC: https://avro.apache.org/docs/current/specification/#protocol-wire-format
+package ipc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aacfactory/avro/internal/base"
+)
+
+const (
+	// ContentType is the content type of Avro RPC over HTTP.
+	ContentType = "avro/binary"
+
+	// DefaultMaxMessageSize is the default maximum size of the requests read by servers,
+	// and the maximum size of the responses read by clients.
+	DefaultMaxMessageSize = 16 * 1024 * 1024
+
+	// DefaultMaxClients is the default maximum number of client protocols kept by servers.
+	DefaultMaxClients = 64
+
+	frameSize = 8192
+)
+
+// ErrMessageTooLarge is returned when a message exceeds the maximum size.
+var ErrMessageTooLarge = errors.New("avro: message exceeds the maximum size")
+
+// Handshake matches.
+const (
+	// MatchBoth means the server knows the protocol of the client, and the client the protocol of the server.
+	MatchBoth = "BOTH"
+	// MatchClient means the server knows the protocol of the client, and sends its own protocol.
+	MatchClient = "CLIENT"
+	// MatchNone means the server does not know the protocol of the client, which must send it.
+	MatchNone = "NONE"
+)
+
+// HandshakeRequestSchema is the Avro schema of a handshake request.
+var HandshakeRequestSchema = base.MustParse(`{
+	"type": "record",
+	"name": "HandshakeRequest",
+	"namespace": "org.apache.avro.ipc",
+	"fields": [
+		{"name": "clientHash", "type": {"type": "fixed", "name": "MD5", "size": 16}},
+		{"name": "clientProtocol", "type": ["null", "string"]},
+		{"name": "serverHash", "type": "MD5"},
+		{"name": "meta", "type": ["null", {"type": "map", "values": "bytes"}]}
+	]
+}`)
+
+// HandshakeResponseSchema is the Avro schema of a handshake response.
+var HandshakeResponseSchema = base.MustParse(`{
+	"type": "record",
+	"name": "HandshakeResponse",
+	"namespace": "org.apache.avro.ipc",
+	"fields": [
+		{"name": "match", "type": {"type": "enum", "name": "HandshakeMatch", "symbols": ["BOTH", "CLIENT", "NONE"]}},
+		{"name": "serverProtocol", "type": ["null", "string"]},
+		{"name": "serverHash", "type": ["null", {"type": "fixed", "name": "MD5", "size": 16}]},
+		{"name": "meta", "type": ["null", {"type": "map", "values": "bytes"}]}
+	]
+}`)
+
+var metaSchema = base.MustParse(`{"type": "map", "values": "bytes"}`)
+
+// stringErrors is the error union of messages declaring no errors.
+var stringErrors = base.Schemas{base.NewPrimitiveSchema(base.String, nil)}
+
+// errorTypesOf returns the types of the error union of the message, the string of undeclared errors
+// followed by its declared errors, which is only the string if the message has no error union.
+func errorTypesOf(msg *base.Message) base.Schemas {
+	if msg.Errors() == nil {
+		return stringErrors
+	}
+	return msg.Errors().Types()
+}
+
+// HandshakeRequest is the handshake sent by clients before their first call.
+type HandshakeRequest struct {
+	ClientHash     [16]byte           `avro:"clientHash"`
+	ClientProtocol *string            `avro:"clientProtocol"`
+	ServerHash     [16]byte           `avro:"serverHash"`
+	Meta           *map[string][]byte `avro:"meta"`
+}
+
+// HandshakeResponse is the handshake returned by servers to handshake requests.
+type HandshakeResponse struct {
+	Match          string             `avro:"match"`
+	ServerProtocol *string            `avro:"serverProtocol"`
+	ServerHash     *[16]byte          `avro:"serverHash"`
+	Meta           *map[string][]byte `avro:"meta"`
+}
+
+// Error is an error returned by a message, of one of the errors declared by the message,
+// or of the string error every message may return.
+type Error struct {
+	// Name is the full name of the error schema, or string.
+	Name string
+	// Value is the error value.
+	Value any
+}
+
+// NewError returns an error of the declared error schema of the name.
+func NewError(name string, value any) *Error {
+	return &Error{Name: name, Value: value}
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	if e.Name == string(base.String) {
+		return fmt.Sprintf("avro: remote error: %v", e.Value)
+	}
+	return fmt.Sprintf("avro: remote error %s: %v", e.Name, e.Value)
+}
+
+// hashOf returns the MD5 hash of the protocol.
+func hashOf(protocol *base.Protocol) (hash [16]byte) {
+	b, _ := hex.DecodeString(protocol.Hash())
+	copy(hash[:], b)
+	return
+}
+
+// nameOf returns the full name of named schemas, and the type of others.
+func nameOf(schema base.Schema) string {
+	if ref, ok := schema.(*base.RefSchema); ok {
+		schema = ref.Schema()
+	}
+	if named, ok := schema.(base.NamedSchema); ok {
+		return named.FullName()
+	}
+	return string(schema.Type())
+}
+
+// writeFrames writes p as a list of buffers, each preceded by its length, and ended by an empty buffer.
+func writeFrames(w io.Writer, p []byte) error {
+	header := make([]byte, 4)
+	for len(p) > 0 {
+		n := len(p)
+		if n > frameSize {
+			n = frameSize
+		}
+		binary.BigEndian.PutUint32(header, uint32(n))
+		if _, err := w.Write(header); err != nil {
+			return err
+		}
+		if _, err := w.Write(p[:n]); err != nil {
+			return err
+		}
+		p = p[n:]
+	}
+	binary.BigEndian.PutUint32(header, 0)
+	_, err := w.Write(header)
+	return err
+}
+
+// readFrames reads buffers until an empty one, and returns their concatenation,
+// failing if it exceeds max bytes.
+func readFrames(r io.Reader, max int) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if buf.Len() > 0 && errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		n := binary.BigEndian.Uint32(header)
+		if n == 0 {
+			return buf.Bytes(), nil
+		}
+		if int64(buf.Len())+int64(n) > int64(max) {
+			return nil, ErrMessageTooLarge
+		}
+		if _, err := io.CopyN(buf, r, int64(n)); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+}
+
+func newReader(p []byte) *base.Reader {
+	return base.NewReader(bytes.NewReader(p), 512)
+}
+
+func newWriter(buf *bytes.Buffer) *base.Writer {
+	return base.NewWriter(buf, 512)
+}
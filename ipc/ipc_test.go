@@ -0,0 +1,267 @@
+package ipc_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aacfactory/avro/internal/base"
+	"github.com/aacfactory/avro/ipc"
+)
+
+const serverProtocol = `{
+	"protocol": "Calculator",
+	"namespace": "test.ipc",
+	"types": [
+		{"type": "error", "name": "NotFound", "fields": [{"name": "key", "type": "string"}]}
+	],
+	"messages": {
+		"add": {"request": [{"name": "a", "type": "int"}, {"name": "b", "type": "int"}], "response": "int"},
+		"get": {"request": [{"name": "key", "type": "string"}], "response": "string", "errors": ["NotFound"]},
+		"reset": {"request": [], "response": "null", "one-way": true}
+	}
+}`
+
+// The client reads the int response of add as a long
+const clientProtocol = `{
+	"protocol": "Calculator",
+	"namespace": "test.ipc",
+	"types": [
+		{"type": "error", "name": "NotFound", "fields": [{"name": "key", "type": "string"}]}
+	],
+	"messages": {
+		"add": {"request": [{"name": "a", "type": "int"}, {"name": "b", "type": "int"}], "response": "long"},
+		"get": {"request": [{"name": "key", "type": "string"}], "response": "string", "errors": ["NotFound"]},
+		"reset": {"request": [], "response": "null", "one-way": true}
+	}
+}`
+
+type AddRequest struct {
+	A int `avro:"a"`
+	B int `avro:"b"`
+}
+
+type NotFound struct {
+	Key string `avro:"key"`
+}
+
+func (e *NotFound) Error() string {
+	return "not found: " + e.Key
+}
+
+func newServer(resets chan struct{}) *ipc.Server {
+	server := ipc.NewServer(base.MustParseProtocol(serverProtocol))
+	_ = server.Handle("add", func(ctx context.Context, req *ipc.Request) (any, error) {
+		r := AddRequest{}
+		if err := req.Decode(&r); err != nil {
+			return nil, err
+		}
+		return r.A + r.B, nil
+	})
+	_ = server.Handle("get", func(ctx context.Context, req *ipc.Request) (any, error) {
+		r := map[string]any{}
+		if err := req.Decode(&r); err != nil {
+			return nil, err
+		}
+		if r["key"] == "missing" {
+			return nil, ipc.NewError("test.ipc.NotFound", NotFound{Key: "missing"})
+		}
+		if r["key"] == "broken" {
+			return nil, errors.New("broken")
+		}
+		return "value of " + r["key"].(string), nil
+	})
+	_ = server.Handle("reset", func(ctx context.Context, req *ipc.Request) (any, error) {
+		resets <- struct{}{}
+		return nil, nil
+	})
+	return server
+}
+
+func testCalls(t *testing.T, client *ipc.Client, resets chan struct{}) {
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		var sum int64
+		if err := client.Call(ctx, "add", AddRequest{A: 1, B: i}, &sum); err != nil {
+			t.Error(err)
+			return
+		}
+		if sum != int64(1+i) {
+			t.Error("add failed", sum)
+			return
+		}
+	}
+	var value string
+	if err := client.Call(ctx, "get", map[string]any{"key": "foo"}, &value); err != nil || value != "value of foo" {
+		t.Error("get failed", value, err)
+		return
+	}
+	err := client.Call(ctx, "get", map[string]any{"key": "missing"}, &value)
+	notFound := &NotFound{}
+	if !errors.As(err, &notFound) || notFound.Key != "missing" {
+		t.Error("declared error failed", err)
+		return
+	}
+	t.Log(err)
+	err = client.Call(ctx, "get", map[string]any{"key": "broken"}, &value)
+	remote := &ipc.Error{}
+	if !errors.As(err, &remote) || remote.Value != "broken" {
+		t.Error("string error failed", err)
+		return
+	}
+	t.Log(err)
+	if err = client.Call(ctx, "reset", map[string]any{}, nil); err != nil {
+		t.Error(err)
+		return
+	}
+	select {
+	case <-resets:
+	case <-time.After(time.Second):
+		t.Error("one-way message is not handled")
+	}
+}
+
+func TestConn(t *testing.T) {
+	resets := make(chan struct{}, 1)
+	server := newServer(resets)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer l.Close()
+	go func() {
+		_ = server.Serve(l)
+	}()
+
+	transceiver, err := ipc.Dial(context.Background(), "tcp", l.Addr().String())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer transceiver.Close()
+	client := ipc.NewClient(base.MustParseProtocol(clientProtocol), transceiver)
+	client.RegisterError("test.ipc.NotFound", &NotFound{})
+	testCalls(t, client, resets)
+}
+
+func TestHTTP(t *testing.T) {
+	resets := make(chan struct{}, 1)
+	srv := httptest.NewServer(newServer(resets))
+	defer srv.Close()
+
+	transceiver := ipc.NewHTTPTransceiver(srv.URL, srv.Client())
+	defer transceiver.Close()
+	client := ipc.NewClient(base.MustParseProtocol(clientProtocol), transceiver)
+	client.RegisterError("test.ipc.NotFound", &NotFound{})
+	testCalls(t, client, resets)
+}
+
+func TestUntrustedRequests(t *testing.T) {
+	server := ipc.NewServer(base.MustParseProtocol(serverProtocol), ipc.WithMaxMessageSize(1024))
+
+	client, conn := net.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- server.ServeConn(conn)
+	}()
+	header := binary.BigEndian.AppendUint32(nil, 1<<30)
+	_, _ = client.Write(header)
+	if err := <-done; !errors.Is(err, ipc.ErrMessageTooLarge) {
+		t.Error("request larger than the maximum size is read", err)
+		return
+	}
+	_ = client.Close()
+
+	text := clientProtocol
+	p, err := base.DefaultConfig.Marshal(ipc.HandshakeRequestSchema, ipc.HandshakeRequest{
+		ClientHash:     [16]byte{1},
+		ClientProtocol: &text,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	client, conn = net.Pipe()
+	defer client.Close()
+	go func() {
+		done <- server.ServeConn(conn)
+	}()
+	req := binary.BigEndian.AppendUint32(nil, uint32(len(p)))
+	req = append(append(req, p...), 0, 0, 0, 0)
+	_, _ = client.Write(req)
+	err = <-done
+	if err == nil {
+		t.Error("client protocol of another hash is accepted")
+		return
+	}
+	t.Log(err)
+}
+
+func TestConnTransceiverFailure(t *testing.T) {
+	for _, timeout := range []bool{false, true} {
+		client, conn := net.Pipe()
+		go func() {
+			_, _ = io.Copy(io.Discard, conn)
+		}()
+		transceiver := ipc.NewConnTransceiver(client)
+		ctx, cancel := context.WithCancel(context.Background())
+		if timeout {
+			ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+		} else {
+			time.AfterFunc(20*time.Millisecond, cancel)
+		}
+		_, err := transceiver.Transceive(ctx, []byte("request"), false)
+		cancel()
+		if !errors.Is(err, ctx.Err()) {
+			t.Error("call without response is not ended by its context", err)
+			return
+		}
+		_, err = transceiver.Transceive(context.Background(), []byte("request"), false)
+		if err == nil {
+			t.Error("failed transceiver is used again")
+			return
+		}
+		t.Log(err)
+		_ = conn.Close()
+	}
+}
+
+func TestMessageWithoutErrors(t *testing.T) {
+	req, err := base.NewRecordSchema("ping", "", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	// Messages built without an error union only have string errors
+	protocol, err := base.NewProtocol("Pinger", "test.ipc", nil, map[string]*base.Message{
+		"ping": base.NewMessage(req, base.NewPrimitiveSchema(base.String, nil), nil, false),
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := ipc.NewServer(protocol)
+	_ = server.Handle("ping", func(ctx context.Context, req *ipc.Request) (any, error) {
+		return nil, errors.New("down")
+	})
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	transceiver := ipc.NewHTTPTransceiver(srv.URL, srv.Client())
+	defer transceiver.Close()
+	client := ipc.NewClient(protocol, transceiver)
+	var pong string
+	err = client.Call(context.Background(), "ping", map[string]any{}, &pong)
+	remote := &ipc.Error{}
+	if !errors.As(err, &remote) || remote.Value != "down" {
+		t.Error("string error of a message without errors failed", err)
+		return
+	}
+	t.Log(err)
+}
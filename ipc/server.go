@@ -0,0 +1,357 @@
+package ipc
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/aacfactory/avro/internal/base"
+)
+
+// Handler handles the calls of a message, returning the response of the message or an error.
+//
+// Errors of type *Error, or whose derived schema is one of the errors declared by the message,
+// are sent as declared errors, and other errors as string errors.
+type Handler func(ctx context.Context, req *Request) (any, error)
+
+// Request is a call of a message.
+type Request struct {
+	// Message is the name of the message.
+	Message string
+	// Meta is the metadata of the call.
+	Meta map[string][]byte
+
+	reader *base.Reader
+	schema base.Schema
+	writer base.Schema
+}
+
+// Decode decodes the request parameters into v, a pointer to a struct or a map.
+func (r *Request) Decode(v any) error {
+	if len(r.schema.(*base.RecordSchema).Fields()) == 0 {
+		return nil
+	}
+	r.reader.ReadValWithWriterSchema(r.schema, r.writer, v)
+	return r.reader.Error
+}
+
+type serverConfig struct {
+	maxMessageSize int
+	maxClients     int
+}
+
+// ServerFunc is a function used to customize the Server.
+type ServerFunc func(*serverConfig)
+
+// WithMaxMessageSize sets the maximum size of the requests read by the server, defaulting to DefaultMaxMessageSize.
+func WithMaxMessageSize(size int) ServerFunc {
+	return func(cfg *serverConfig) {
+		cfg.maxMessageSize = size
+	}
+}
+
+// WithMaxClients sets the maximum number of client protocols kept by the server, defaulting to DefaultMaxClients.
+// Clients of other protocols send their protocol with each handshake.
+func WithMaxClients(n int) ServerFunc {
+	return func(cfg *serverConfig) {
+		cfg.maxClients = n
+	}
+}
+
+// Server serves the messages of a protocol.
+type Server struct {
+	protocol *base.Protocol
+	hash     [16]byte
+	text     string
+	cfg      serverConfig
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	clientsMu sync.RWMutex
+	clients   map[[16]byte]*base.Protocol
+}
+
+// NewServer returns a server of the protocol.
+func NewServer(protocol *base.Protocol, opts ...ServerFunc) *Server {
+	cfg := serverConfig{maxMessageSize: DefaultMaxMessageSize, maxClients: DefaultMaxClients}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	s := &Server{
+		protocol: protocol,
+		hash:     hashOf(protocol),
+		text:     protocol.Declaration(),
+		cfg:      cfg,
+		handlers: map[string]Handler{},
+		clients:  map[[16]byte]*base.Protocol{},
+	}
+	s.clients[s.hash] = protocol
+	return s
+}
+
+// Handle sets the handler of the message.
+func (s *Server) Handle(message string, handler Handler) error {
+	if s.protocol.Message(message) == nil {
+		return fmt.Errorf("avro: protocol %s has no message %s", s.protocol.FullName(), message)
+	}
+	s.mu.Lock()
+	s.handlers[message] = handler
+	s.mu.Unlock()
+	return nil
+}
+
+// Serve accepts connections on the listener, and serves each of them in a goroutine.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			_ = s.ServeConn(conn)
+		}()
+	}
+}
+
+// ServeConn serves the calls of the connection until it is closed. The client handshakes once per connection.
+func (s *Server) ServeConn(conn net.Conn) error {
+	defer conn.Close()
+
+	ctx := context.Background()
+	var client *base.Protocol
+	for {
+		req, err := readFrames(conn, s.cfg.maxMessageSize)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		resp, next, err := s.respond(ctx, req, client)
+		if err != nil {
+			return err
+		}
+		client = next
+		if resp == nil {
+			continue
+		}
+		if err = writeFrames(conn, resp); err != nil {
+			return err
+		}
+	}
+}
+
+// ServeHTTP serves a call posted to the handler. The client handshakes on every call.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	req, err := readFrames(r.Body, s.cfg.maxMessageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, _, err := s.respond(r.Context(), req, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	_ = writeFrames(w, resp)
+}
+
+// respond returns the response of the request and the protocol of the client, which is nil
+// until the client handshakes. The response is nil for one-way messages of handshaken clients.
+func (s *Server) respond(ctx context.Context, req []byte, client *base.Protocol) ([]byte, *base.Protocol, error) {
+	r := newReader(req)
+	buf := bytes.NewBuffer(nil)
+	w := newWriter(buf)
+
+	withHandshake := client == nil
+	if withHandshake {
+		client = s.handshake(r, w)
+		if r.Error != nil {
+			return nil, nil, fmt.Errorf("avro: read handshake failed, %w", r.Error)
+		}
+		if client == nil {
+			// The client sends its protocol with its next request
+			if err := w.Flush(); err != nil {
+				return nil, nil, err
+			}
+			return buf.Bytes(), nil, nil
+		}
+	}
+
+	var meta map[string][]byte
+	r.ReadVal(metaSchema, &meta)
+	name := r.ReadString()
+	if r.Error != nil {
+		return nil, nil, fmt.Errorf("avro: read call failed, %w", r.Error)
+	}
+
+	msg := s.protocol.Message(name)
+	clientMsg := client.Message(name)
+	if msg == nil || clientMsg == nil {
+		s.writeError(w, nil, fmt.Errorf("avro: protocol %s has no message %s", s.protocol.FullName(), name))
+		return s.flush(buf, w, client)
+	}
+	s.mu.RLock()
+	handler := s.handlers[name]
+	s.mu.RUnlock()
+
+	var resp any
+	var err error
+	if handler == nil {
+		err = fmt.Errorf("avro: message %s is not handled", name)
+	} else {
+		resp, err = handler(ctx, &Request{
+			Message: name,
+			Meta:    meta,
+			reader:  r,
+			schema:  msg.Request(),
+			writer:  clientMsg.Request(),
+		})
+	}
+	if msg.OneWay() {
+		if !withHandshake {
+			return nil, client, nil
+		}
+		return s.flush(buf, w, client)
+	}
+
+	if err == nil && msg.Response() != nil {
+		// The response is encoded apart, as it is replaced by an error if it fails
+		payload := bytes.NewBuffer(nil)
+		pw := newWriter(payload)
+		pw.WriteVal(msg.Response(), resp)
+		if pw.Error == nil {
+			pw.Error = pw.Flush()
+		}
+		if pw.Error != nil {
+			err = fmt.Errorf("avro: write response of %s failed, %w", name, pw.Error)
+		} else {
+			w.WriteVal(metaSchema, map[string][]byte{})
+			w.WriteBool(false)
+			_, _ = w.Write(payload.Bytes())
+			return s.flush(buf, w, client)
+		}
+	}
+	if err != nil {
+		s.writeError(w, msg, err)
+		return s.flush(buf, w, client)
+	}
+	w.WriteVal(metaSchema, map[string][]byte{})
+	w.WriteBool(false)
+	return s.flush(buf, w, client)
+}
+
+func (s *Server) flush(buf *bytes.Buffer, w *base.Writer, client *base.Protocol) ([]byte, *base.Protocol, error) {
+	if w.Error != nil {
+		return nil, nil, w.Error
+	}
+	if err := w.Flush(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), client, nil
+}
+
+// handshake reads the handshake request and writes its response, returning the protocol
+// of the client or nil if the server does not know it.
+func (s *Server) handshake(r *base.Reader, w *base.Writer) *base.Protocol {
+	req := HandshakeRequest{}
+	r.ReadVal(HandshakeRequestSchema, &req)
+	if r.Error != nil {
+		return nil
+	}
+
+	var client *base.Protocol
+	if req.ClientProtocol != nil {
+		parsed, err := base.ParseProtocol(*req.ClientProtocol)
+		if err != nil {
+			r.Error = fmt.Errorf("avro: parse client protocol failed, %w", err)
+			return nil
+		}
+		// The hash is the MD5 of the protocol text, or of its canonical form
+		if md5.Sum([]byte(*req.ClientProtocol)) != req.ClientHash && hashOf(parsed) != req.ClientHash {
+			r.Error = errors.New("avro: client hash is not the hash of the client protocol")
+			return nil
+		}
+		client = parsed
+		s.addClient(req.ClientHash, client)
+	} else {
+		client = s.clientOf(req.ClientHash)
+	}
+
+	resp := HandshakeResponse{Match: MatchBoth}
+	switch {
+	case client == nil:
+		resp.Match = MatchNone
+	case req.ServerHash != s.hash:
+		resp.Match = MatchClient
+	}
+	if resp.Match != MatchBoth {
+		resp.ServerProtocol = &s.text
+		resp.ServerHash = &s.hash
+	}
+	w.WriteVal(HandshakeResponseSchema, resp)
+	return client
+}
+
+// addClient keeps the protocol of the client, unless the server keeps the maximum number of clients.
+func (s *Server) addClient(hash [16]byte, client *base.Protocol) {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	if _, ok := s.clients[hash]; ok || len(s.clients) >= s.cfg.maxClients {
+		return
+	}
+	s.clients[hash] = client
+}
+
+// clientOf returns the kept protocol of the hash, or nil.
+func (s *Server) clientOf(hash [16]byte) *base.Protocol {
+	s.clientsMu.RLock()
+	defer s.clientsMu.RUnlock()
+	return s.clients[hash]
+}
+
+// writeError writes the error as a declared error of the message, or as a string error.
+func (s *Server) writeError(w *base.Writer, msg *base.Message, err error) {
+	w.WriteVal(metaSchema, map[string][]byte{})
+	w.WriteBool(true)
+	if msg != nil {
+		types := errorTypesOf(msg)
+		var declared *Error
+		if !errors.As(err, &declared) {
+			declared = nil
+			if schema, parseErr := base.ParseValue(err); parseErr == nil {
+				declared = &Error{Name: nameOf(schema), Value: err}
+			}
+		}
+		for i := 1; declared != nil && i < len(types); i++ {
+			if nameOf(types[i]) != declared.Name {
+				continue
+			}
+			// The error is written apart, as it is replaced by a string error if it fails
+			buf := bytes.NewBuffer(nil)
+			ew := newWriter(buf)
+			ew.WriteVal(types[i], declared.Value)
+			if ew.Error == nil && ew.Flush() == nil {
+				w.WriteLong(int64(i))
+				_, _ = w.Write(buf.Bytes())
+				return
+			}
+			break
+		}
+	}
+	w.WriteLong(0)
+	w.WriteString(err.Error())
+}
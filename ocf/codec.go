@@ -0,0 +1,90 @@
+package ocf
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+)
+
+// CodecName represents a compression codec name.
+type CodecName string
+
+// Supported compression codecs.
+const (
+	Null    CodecName = "null"
+	Deflate CodecName = "deflate"
+)
+
+// Codec represents a block compression codec.
+type Codec interface {
+	// Decode decodes the given bytes.
+	Decode([]byte) ([]byte, error)
+	// Encode encodes the given bytes.
+	Encode([]byte) []byte
+}
+
+// resolveCodec returns the codec of the name, decompressing blocks of up to maxSize bytes,
+// or of any size if it is 0 or less as for WithMaxBlockSize.
+func resolveCodec(name CodecName, lvl int, maxSize int) (Codec, error) {
+	switch name {
+	case Null, "":
+		return &NullCodec{}, nil
+
+	case Deflate:
+		return &DeflateCodec{compLvl: lvl, maxSize: maxSize}, nil
+
+	default:
+		return nil, fmt.Errorf("avro: unknown codec %s", name)
+	}
+}
+
+// NullCodec is a no op codec.
+type NullCodec struct{}
+
+// Decode decodes the given bytes.
+func (*NullCodec) Decode(b []byte) ([]byte, error) {
+	return b, nil
+}
+
+// Encode encodes the given bytes.
+func (*NullCodec) Encode(b []byte) []byte {
+	return b
+}
+
+// DeflateCodec is a flate compression codec.
+type DeflateCodec struct {
+	compLvl int
+	maxSize int
+}
+
+// Decode decodes the given bytes.
+func (c *DeflateCodec) Decode(b []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewBuffer(b))
+	var src io.Reader = r
+	if c.maxSize > 0 {
+		src = io.LimitReader(r, int64(c.maxSize)+1)
+	}
+	data, err := io.ReadAll(src)
+	if err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+	_ = r.Close()
+	if c.maxSize > 0 && len(data) > c.maxSize {
+		return nil, ErrBlockTooLarge
+	}
+
+	return data, nil
+}
+
+// Encode encodes the given bytes.
+func (c *DeflateCodec) Encode(b []byte) []byte {
+	data := bytes.NewBuffer(make([]byte, 0, len(b)))
+
+	w, _ := flate.NewWriter(data, c.compLvl)
+	_, _ = w.Write(b)
+	_ = w.Close()
+
+	return data.Bytes()
+}
@@ -0,0 +1,428 @@
+// Package ocf implements encoding and decoding of Avro Object Container Files as defined by the Avro specification.
+//
+// See the Avro specification for an understanding of Avro: http://avro.apache.org/docs/current/
+package ocf
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aacfactory/avro"
+)
+
+const (
+	schemaKey = "avro.schema"
+	codecKey  = "avro.codec"
+)
+
+// DefaultMaxBlockSize is the maximum size of the blocks read by decoders, before and after decompression,
+// unless set by WithMaxBlockSize.
+const DefaultMaxBlockSize = 64 * 1024 * 1024
+
+// ErrBlockTooLarge is returned when a block exceeds the maximum block size of the decoder.
+var ErrBlockTooLarge = errors.New("decoder: block exceeds the maximum block size")
+
+var magicBytes = [4]byte{'O', 'b', 'j', 1}
+
+// HeaderSchema is the Avro schema of a container file header.
+var HeaderSchema = avro.MustParse(`{
+	"type": "record",
+	"name": "org.apache.avro.file.Header",
+	"fields": [
+		{"name": "magic", "type": {"type": "fixed", "name": "Magic", "size": 4}},
+		{"name": "meta", "type": {"type": "map", "values": "bytes"}},
+		{"name": "sync", "type": {"type": "fixed", "name": "Sync", "size": 16}}
+	]
+}`)
+
+// Header represents an Avro container file header.
+type Header struct {
+	Magic [4]byte           `avro:"magic"`
+	Meta  map[string][]byte `avro:"meta"`
+	Sync  [16]byte          `avro:"sync"`
+}
+
+type decoderConfig struct {
+	DecoderConfig avro.API
+	MaxBlockSize  int
+}
+
+// DecoderFunc represents a configuration function for Decoder.
+type DecoderFunc func(cfg *decoderConfig)
+
+// WithDecoderConfig sets the value decoder config on the OCF decoder.
+func WithDecoderConfig(wCfg avro.API) DecoderFunc {
+	return func(cfg *decoderConfig) {
+		cfg.DecoderConfig = wCfg
+	}
+}
+
+// WithMaxBlockSize sets the maximum size of the blocks read by the decoder, before and after decompression,
+// as the size of a block is read from the file. A size of 0 or less reads blocks of any size.
+func WithMaxBlockSize(size int) DecoderFunc {
+	return func(cfg *decoderConfig) {
+		cfg.MaxBlockSize = size
+	}
+}
+
+// Decoder reads and decodes Avro values from a container file.
+type Decoder struct {
+	reader  *avro.Reader
+	decoder *avro.Reader
+	meta    map[string][]byte
+	sync    [16]byte
+	schema  avro.Schema
+	codec   Codec
+	count   int64
+	maxSize int
+}
+
+// NewDecoder returns a new decoder that reads from reader r.
+func NewDecoder(r io.Reader, opts ...DecoderFunc) (*Decoder, error) {
+	cfg := decoderConfig{
+		DecoderConfig: avro.DefaultConfig,
+		MaxBlockSize:  DefaultMaxBlockSize,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	reader := avro.NewReader(r, 1024, avro.WithReaderConfig(cfg.DecoderConfig))
+
+	h, err := readHeader(reader, cfg.MaxBlockSize)
+	if err != nil {
+		return nil, fmt.Errorf("decoder: %w", err)
+	}
+
+	return &Decoder{
+		reader:  reader,
+		decoder: avro.NewReader(nil, 0, avro.WithReaderConfig(cfg.DecoderConfig)),
+		meta:    h.Meta,
+		sync:    h.Sync,
+		schema:  h.Schema,
+		codec:   h.Codec,
+		maxSize: cfg.MaxBlockSize,
+	}, nil
+}
+
+// Metadata returns the header metadata.
+func (d *Decoder) Metadata() map[string][]byte {
+	return d.meta
+}
+
+// Schema returns the schema that was used to write the file.
+func (d *Decoder) Schema() avro.Schema {
+	return d.schema
+}
+
+// HasNext determines if there is another value to read.
+func (d *Decoder) HasNext() bool {
+	if d.count <= 0 {
+		count := d.readBlock()
+		d.count = count
+	}
+
+	if d.reader.Error != nil || (d.decoder.Error != nil && !errors.Is(d.decoder.Error, io.EOF)) {
+		return false
+	}
+
+	return d.count > 0
+}
+
+// Decode reads the next Avro encoded value from its input and stores it in the value pointed to by v.
+func (d *Decoder) Decode(v any) error {
+	if d.count <= 0 {
+		return errors.New("decoder: no data found, call HasNext first")
+	}
+
+	d.count--
+
+	d.decoder.ReadVal(d.schema, v)
+	if err := d.decoder.Error; err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// Error returns the last reader error.
+func (d *Decoder) Error() error {
+	if err := d.reader.Error; err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if err := d.decoder.Error; err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func (d *Decoder) readBlock() int64 {
+	count := d.reader.ReadLong()
+	size := d.reader.ReadLong()
+	if d.reader.Error != nil {
+		return 0
+	}
+	if count < 0 || size < 0 {
+		d.reader.Error = errors.New("decoder: invalid block header")
+		return 0
+	}
+	if d.maxSize > 0 && size > int64(d.maxSize) {
+		d.reader.Error = ErrBlockTooLarge
+		return 0
+	}
+
+	if count > 0 {
+		data := make([]byte, size)
+		d.reader.Read(data)
+
+		data, err := d.codec.Decode(data)
+		d.decoder.Reset(data)
+		d.decoder.Error = err
+	}
+
+	var sync [16]byte
+	d.reader.Read(sync[:])
+	if d.reader.Error != nil {
+		return 0
+	}
+	if d.sync != sync {
+		d.reader.Error = errors.New("decoder: invalid block")
+		return 0
+	}
+
+	return count
+}
+
+type encoderConfig struct {
+	BlockLength      int
+	CodecName        CodecName
+	CodecCompression int
+	Metadata         map[string][]byte
+	Sync             [16]byte
+	EncodingConfig   avro.API
+}
+
+// EncoderFunc represents a configuration function for Encoder.
+type EncoderFunc func(cfg *encoderConfig)
+
+// WithBlockLength sets the block length on the encoder.
+func WithBlockLength(length int) EncoderFunc {
+	return func(cfg *encoderConfig) {
+		cfg.BlockLength = length
+	}
+}
+
+// WithCodec sets the compression codec on the encoder.
+func WithCodec(codec CodecName) EncoderFunc {
+	return func(cfg *encoderConfig) {
+		cfg.CodecName = codec
+	}
+}
+
+// WithCompressionLevel sets the compression codec to deflate and
+// the compression level on the encoder.
+func WithCompressionLevel(compLvl int) EncoderFunc {
+	return func(cfg *encoderConfig) {
+		cfg.CodecName = Deflate
+		cfg.CodecCompression = compLvl
+	}
+}
+
+// WithMetadata sets the metadata on the encoder header.
+func WithMetadata(meta map[string][]byte) EncoderFunc {
+	return func(cfg *encoderConfig) {
+		cfg.Metadata = meta
+	}
+}
+
+// WithSyncBlock sets the sync block.
+func WithSyncBlock(sync [16]byte) EncoderFunc {
+	return func(cfg *encoderConfig) {
+		cfg.Sync = sync
+	}
+}
+
+// WithEncodingConfig sets the value encoder config on the OCF encoder.
+func WithEncodingConfig(wCfg avro.API) EncoderFunc {
+	return func(cfg *encoderConfig) {
+		cfg.EncodingConfig = wCfg
+	}
+}
+
+// Encoder writes Avro container file to an output stream.
+type Encoder struct {
+	writer      *avro.Writer
+	encoder     *avro.Writer
+	sync        [16]byte
+	codec       Codec
+	schema      avro.Schema
+	blockLength int
+	count       int
+}
+
+// NewEncoder returns a new encoder that writes to w using schema s.
+func NewEncoder(s string, w io.Writer, opts ...EncoderFunc) (*Encoder, error) {
+	schema, err := avro.ParseWithCache(s, "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, err
+	}
+	return NewEncoderWithSchema(schema, w, opts...)
+}
+
+// NewEncoderWithSchema returns a new encoder that writes to w using schema.
+func NewEncoderWithSchema(schema avro.Schema, w io.Writer, opts ...EncoderFunc) (*Encoder, error) {
+	cfg := encoderConfig{
+		BlockLength:      100,
+		CodecName:        Null,
+		CodecCompression: -1,
+		Metadata:         map[string][]byte{},
+		EncodingConfig:   avro.DefaultConfig,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.BlockLength <= 0 {
+		cfg.BlockLength = 100
+	}
+
+	codec, err := resolveCodec(cfg.CodecName, cfg.CodecCompression, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := make(map[string][]byte, len(cfg.Metadata)+2)
+	for k, v := range cfg.Metadata {
+		meta[k] = v
+	}
+	meta[schemaKey] = []byte(schema.String())
+	meta[codecKey] = []byte(cfg.CodecName)
+
+	header := Header{
+		Magic: magicBytes,
+		Meta:  meta,
+	}
+	header.Sync = cfg.Sync
+	if header.Sync == [16]byte{} {
+		_, _ = rand.Read(header.Sync[:])
+	}
+
+	writer := avro.NewWriter(w, 512, avro.WithWriterConfig(cfg.EncodingConfig))
+	writer.WriteVal(HeaderSchema, header)
+	if err = writer.Flush(); err != nil {
+		return nil, err
+	}
+	if writer.Error != nil {
+		return nil, writer.Error
+	}
+
+	return &Encoder{
+		writer:      writer,
+		encoder:     avro.NewWriter(nil, 512, avro.WithWriterConfig(cfg.EncodingConfig)),
+		sync:        header.Sync,
+		codec:       codec,
+		schema:      schema,
+		blockLength: cfg.BlockLength,
+	}, nil
+}
+
+// Write v to the internal buffer. This method skips the internal encoder and
+// therefore the caller is responsible for encoding the bytes. No error will be
+// thrown if the bytes does not conform to the schema given to NewEncoder, but
+// the final ocf data will be corrupted.
+func (e *Encoder) Write(p []byte) (n int, err error) {
+	n, err = e.encoder.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	e.count++
+	if e.count >= e.blockLength {
+		if err = e.writerBlock(); err != nil {
+			return n, err
+		}
+	}
+
+	return n, e.writer.Error
+}
+
+// Encode writes the Avro encoding of v to the stream.
+func (e *Encoder) Encode(v any) error {
+	e.encoder.WriteVal(e.schema, v)
+	if e.encoder.Error != nil {
+		return e.encoder.Error
+	}
+
+	e.count++
+	if e.count >= e.blockLength {
+		if err := e.writerBlock(); err != nil {
+			return err
+		}
+	}
+
+	return e.writer.Error
+}
+
+// Flush flushes the underlying writer.
+func (e *Encoder) Flush() error {
+	if e.count == 0 {
+		return nil
+	}
+
+	return e.writerBlock()
+}
+
+// Close closes the encoder, flushing the writer.
+func (e *Encoder) Close() error {
+	return e.Flush()
+}
+
+func (e *Encoder) writerBlock() error {
+	e.writer.WriteLong(int64(e.count))
+
+	b := e.codec.Encode(e.encoder.Buffer())
+
+	e.writer.WriteLong(int64(len(b)))
+	_, _ = e.writer.Write(b)
+
+	_, _ = e.writer.Write(e.sync[:])
+
+	e.count = 0
+	e.encoder.Reset(nil)
+	return e.writer.Flush()
+}
+
+type ocfHeader struct {
+	Schema avro.Schema
+	Codec  Codec
+	Meta   map[string][]byte
+	Sync   [16]byte
+}
+
+func readHeader(reader *avro.Reader, maxBlockSize int) (*ocfHeader, error) {
+	var h Header
+	reader.ReadVal(HeaderSchema, &h)
+	if reader.Error != nil {
+		return nil, fmt.Errorf("unexpected error: %w", reader.Error)
+	}
+
+	if h.Magic != magicBytes {
+		return nil, errors.New("invalid avro file")
+	}
+	schema, err := avro.ParseBytesWithCache(h.Meta[schemaKey], "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := resolveCodec(CodecName(h.Meta[codecKey]), -1, maxBlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ocfHeader{
+		Schema: schema,
+		Codec:  codec,
+		Meta:   h.Meta,
+		Sync:   h.Sync,
+	}, nil
+}
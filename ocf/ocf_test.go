@@ -0,0 +1,176 @@
+package ocf_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aacfactory/avro"
+	"github.com/aacfactory/avro/ocf"
+)
+
+type Record struct {
+	Id   int64    `avro:"id"`
+	Name string   `avro:"name"`
+	Tags []string `avro:"tags"`
+}
+
+const recordSchema = `{"type":"record","name":"test.Record","fields":[{"name":"id","type":"long"},{"name":"name","type":"string"},{"name":"tags","type":{"type":"array","items":"string"}}]}`
+
+func TestEncoderDecoder(t *testing.T) {
+	for _, codec := range []ocf.CodecName{ocf.Null, ocf.Deflate} {
+		buf := bytes.NewBuffer(nil)
+		enc, encErr := ocf.NewEncoder(recordSchema, buf, ocf.WithCodec(codec), ocf.WithBlockLength(2))
+		if encErr != nil {
+			t.Error(encErr)
+			return
+		}
+		for i := 0; i < 5; i++ {
+			if err := enc.Encode(Record{Id: int64(i), Name: "record", Tags: []string{"a", "b"}}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Error(err)
+			return
+		}
+
+		dec, decErr := ocf.NewDecoder(buf)
+		if decErr != nil {
+			t.Error(decErr)
+			return
+		}
+		if string(dec.Metadata()["avro.codec"]) != string(codec) {
+			t.Errorf("codec: want %s, got %s", codec, dec.Metadata()["avro.codec"])
+			return
+		}
+		t.Log(dec.Schema())
+		n := 0
+		for dec.HasNext() {
+			r := Record{}
+			if err := dec.Decode(&r); err != nil {
+				t.Error(err)
+				return
+			}
+			if r.Id != int64(n) || r.Name != "record" || len(r.Tags) != 2 {
+				t.Errorf("unexpected record %+v", r)
+				return
+			}
+			n++
+		}
+		if err := dec.Error(); err != nil {
+			t.Error(err)
+			return
+		}
+		if n != 5 {
+			t.Errorf("want 5 records, got %d", n)
+		}
+	}
+}
+
+type Item struct {
+	Name string `avro:"name"`
+	Next *Item  `avro:"next"`
+}
+
+type Order struct {
+	Id    int64  `avro:"id"`
+	Main  Item   `avro:"main"`
+	Items []Item `avro:"items"`
+}
+
+func TestEncoderWithValueSchema(t *testing.T) {
+	schema, schemaErr := avro.DefaultConfig.ParseValue(Order{})
+	if schemaErr != nil {
+		t.Error(schemaErr)
+		return
+	}
+	buf := bytes.NewBuffer(nil)
+	enc, encErr := ocf.NewEncoderWithSchema(schema, buf)
+	if encErr != nil {
+		t.Error(encErr)
+		return
+	}
+	order := Order{Id: 1, Main: Item{Name: "main", Next: &Item{Name: "next"}}, Items: []Item{{Name: "item"}}}
+	if err := enc.Encode(order); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := enc.Close(); err != nil {
+		t.Error(err)
+		return
+	}
+	dec, decErr := ocf.NewDecoder(buf)
+	if decErr != nil {
+		t.Error(decErr)
+		return
+	}
+	for dec.HasNext() {
+		r := Order{}
+		if err := dec.Decode(&r); err != nil {
+			t.Error(err)
+			return
+		}
+		if r.Main.Next == nil || r.Main.Next.Name != "next" || len(r.Items) != 1 {
+			t.Errorf("unexpected order %+v", r)
+			return
+		}
+	}
+	if err := dec.Error(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMaxBlockSize(t *testing.T) {
+	for _, codec := range []ocf.CodecName{ocf.Null, ocf.Deflate} {
+		buf := bytes.NewBuffer(nil)
+		enc, encErr := ocf.NewEncoder(recordSchema, buf, ocf.WithCodec(codec))
+		if encErr != nil {
+			t.Error(encErr)
+			return
+		}
+		if err := enc.Encode(Record{Id: 1, Name: strings.Repeat("record", 1024)}); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := enc.Close(); err != nil {
+			t.Error(err)
+			return
+		}
+
+		file := buf.Bytes()
+
+		// No size is no limit
+		dec, decErr := ocf.NewDecoder(bytes.NewReader(file), ocf.WithMaxBlockSize(0))
+		if decErr != nil {
+			t.Error(decErr)
+			return
+		}
+		if !dec.HasNext() {
+			t.Errorf("%s: block is not read without limit, %v", codec, dec.Error())
+			return
+		}
+		r := Record{}
+		if err := dec.Decode(&r); err != nil || len(r.Name) != 6*1024 {
+			t.Errorf("%s: block is not decoded without limit, %v", codec, err)
+			return
+		}
+
+		dec, decErr = ocf.NewDecoder(bytes.NewReader(file), ocf.WithMaxBlockSize(1024))
+		if decErr != nil {
+			t.Error(decErr)
+			return
+		}
+		if dec.HasNext() {
+			t.Errorf("%s: oversized block is read", codec)
+			return
+		}
+		if err := dec.Error(); !errors.Is(err, ocf.ErrBlockTooLarge) {
+			t.Errorf("%s: want %v, got %v", codec, ocf.ErrBlockTooLarge, err)
+			return
+		}
+		t.Log(codec, dec.Error())
+	}
+}
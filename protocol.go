@@ -0,0 +1,63 @@
+package avro
+
+import (
+	"github.com/aacfactory/avro/internal/base"
+)
+
+type (
+	// Protocol is an Avro protocol.
+	Protocol = base.Protocol
+	// Message is an Avro protocol message.
+	Message = base.Message
+	// ProtocolOption is a function that sets a protocol option.
+	ProtocolOption = base.ProtocolOption
+)
+
+// WithProtoDoc sets the doc on a protocol.
+func WithProtoDoc(doc string) ProtocolOption {
+	return base.WithProtoDoc(doc)
+}
+
+// WithProtoProps sets the properties on a protocol.
+func WithProtoProps(props map[string]any) ProtocolOption {
+	return base.WithProtoProps(props)
+}
+
+// NewProtocol creates a protocol instance.
+func NewProtocol(name, namespace string, types []NamedSchema, messages map[string]*Message, opts ...ProtocolOption) (*Protocol, error) {
+	return base.NewProtocol(name, namespace, types, messages, opts...)
+}
+
+// NewMessage creates a protocol message instance.
+func NewMessage(req *RecordSchema, resp Schema, errors *UnionSchema, oneWay bool, opts ...ProtocolOption) *Message {
+	return base.NewMessage(req, resp, errors, oneWay, opts...)
+}
+
+// ParseProtocolFile parses an Avro protocol from a file.
+func ParseProtocolFile(path string) (*Protocol, error) {
+	return base.ParseProtocolFile(path)
+}
+
+// MustParseProtocol parses an Avro protocol, panicing if there is an error.
+func MustParseProtocol(protocol string) *Protocol {
+	return base.MustParseProtocol(protocol)
+}
+
+// ParseProtocol parses an Avro protocol.
+func ParseProtocol(protocol string) (*Protocol, error) {
+	return base.ParseProtocol(protocol)
+}
+
+// ProtocolOf derives the protocol of an interface, given as a nil pointer to it: ProtocolOf((*Service)(nil)).
+//
+// Each method Foo(ctx context.Context, req Req) (Resp, error) is a message foo of the fields of Req and of
+// response Resp. Methods returning only an error have a null response, and methods returning nothing are one-way.
+// The errors of messages are the errors set by WithErrors.
+func ProtocolOf(v any, opts ...ProtocolOption) (*Protocol, error) {
+	return base.ProtocolOf(v, opts...)
+}
+
+// WithErrors sets the errors of the messages of protocols derived by ProtocolOf, in the given order.
+func WithErrors(errs ...error) ProtocolOption {
+	return base.WithErrors(errs...)
+}
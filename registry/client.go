@@ -0,0 +1,374 @@
+// Package registry implements a Confluent Schema Registry compliant client and the
+// Confluent wire format for Avro encoded data.
+//
+// See the Confluent Schema Registry docs for an understanding of the API:
+// https://docs.confluent.io/current/schema-registry/docs/api.html
+package registry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aacfactory/avro/internal/base"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// Registry represents a schema registry.
+type Registry interface {
+	// GetSchema returns the schema with the given id.
+	GetSchema(ctx context.Context, id int) (base.Schema, error)
+
+	// GetSubjects gets the registry subjects.
+	GetSubjects(ctx context.Context) ([]string, error)
+
+	// GetVersions gets the schema versions for a subject.
+	GetVersions(ctx context.Context, subject string) ([]int, error)
+
+	// GetSchemaByVersion gets the schema by version.
+	GetSchemaByVersion(ctx context.Context, subject string, version int) (base.Schema, error)
+
+	// GetLatestSchema gets the latest schema for a subject.
+	GetLatestSchema(ctx context.Context, subject string) (base.Schema, error)
+
+	// GetSchemaInfo gets the schema and schema metadata for a subject and version.
+	GetSchemaInfo(ctx context.Context, subject string, version int) (SchemaInfo, error)
+
+	// GetLatestSchemaInfo gets the latest schema and schema metadata for a subject.
+	GetLatestSchemaInfo(ctx context.Context, subject string) (SchemaInfo, error)
+
+	// CreateSchema creates a schema in the registry, returning the schema id.
+	CreateSchema(ctx context.Context, subject, schema string) (int, base.Schema, error)
+
+	// IsRegistered determines if the schema is registered, returning the schema id.
+	IsRegistered(ctx context.Context, subject, schema string) (int, base.Schema, error)
+
+	// IsCompatible determines if the schema is compatible with the given subject version.
+	IsCompatible(ctx context.Context, subject string, version int, schema string) (bool, error)
+}
+
+// SchemaInfo represents a schema and metadata information.
+type SchemaInfo struct {
+	Schema  base.Schema
+	ID      int
+	Version int
+}
+
+type schemaPayload struct {
+	Schema string `json:"schema"`
+}
+
+type idPayload struct {
+	ID int `json:"id"`
+}
+
+type schemaInfoPayload struct {
+	Schema  string `json:"schema"`
+	ID      int    `json:"id"`
+	Version int    `json:"version"`
+}
+
+type compatPayload struct {
+	IsCompatible bool `json:"is_compatible"`
+}
+
+type credentials struct {
+	username string
+	password string
+}
+
+var defaultClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   15 * time.Second,
+			KeepAlive: 90 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout: 3 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	Timeout: 10 * time.Second,
+}
+
+// ClientFunc is a function used to customize the Client.
+type ClientFunc func(*Client)
+
+// WithHTTPClient sets the http client to make requests with.
+func WithHTTPClient(client *http.Client) ClientFunc {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithBasicAuth sets the credentials to perform http basic auth.
+func WithBasicAuth(username, password string) ClientFunc {
+	return func(c *Client) {
+		c.creds = credentials{username: username, password: password}
+	}
+}
+
+// Client is an HTTP registry client.
+type Client struct {
+	client *http.Client
+	base   *url.URL
+	creds  credentials
+
+	cache sync.Map // map[int]base.Schema
+}
+
+// NewClient creates a schema registry Client with the given base url.
+func NewClient(baseURL string, opts ...ClientFunc) (*Client, error) {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		client: defaultClient,
+		base:   u,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// GetSchema returns the schema with the given id.
+//
+// GetSchema will cache the schema in memory after it is successfully returned,
+// allowing it to be used efficiently in a high load situation.
+func (c *Client) GetSchema(ctx context.Context, id int) (base.Schema, error) {
+	if schema, ok := c.cache.Load(id); ok {
+		return schema.(base.Schema), nil
+	}
+
+	var payload schemaPayload
+	if err := c.request(ctx, http.MethodGet, "schemas/ids/"+strconv.Itoa(id), nil, &payload); err != nil {
+		return nil, err
+	}
+
+	schema, err := parseSchema(payload.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.Store(id, schema)
+
+	return schema, nil
+}
+
+// GetSubjects gets the registry subjects.
+func (c *Client) GetSubjects(ctx context.Context) ([]string, error) {
+	var subjects []string
+	if err := c.request(ctx, http.MethodGet, "subjects", nil, &subjects); err != nil {
+		return nil, err
+	}
+
+	return subjects, nil
+}
+
+// GetVersions gets the schema versions for a subject.
+func (c *Client) GetVersions(ctx context.Context, subject string) ([]int, error) {
+	var versions []int
+	if err := c.request(ctx, http.MethodGet, "subjects/"+url.PathEscape(subject)+"/versions", nil, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// GetSchemaByVersion gets the schema by version.
+func (c *Client) GetSchemaByVersion(ctx context.Context, subject string, version int) (base.Schema, error) {
+	info, err := c.GetSchemaInfo(ctx, subject, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.Schema, nil
+}
+
+// GetLatestSchema gets the latest schema for a subject.
+func (c *Client) GetLatestSchema(ctx context.Context, subject string) (base.Schema, error) {
+	info, err := c.GetLatestSchemaInfo(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.Schema, nil
+}
+
+// GetSchemaInfo gets the schema and schema metadata for a subject and version.
+func (c *Client) GetSchemaInfo(ctx context.Context, subject string, version int) (SchemaInfo, error) {
+	return c.getSchemaInfo(ctx, subject, strconv.Itoa(version))
+}
+
+// GetLatestSchemaInfo gets the latest schema and schema metadata for a subject.
+func (c *Client) GetLatestSchemaInfo(ctx context.Context, subject string) (SchemaInfo, error) {
+	return c.getSchemaInfo(ctx, subject, "latest")
+}
+
+func (c *Client) getSchemaInfo(ctx context.Context, subject, version string) (SchemaInfo, error) {
+	var payload schemaInfoPayload
+	p := "subjects/" + url.PathEscape(subject) + "/versions/" + version
+	if err := c.request(ctx, http.MethodGet, p, nil, &payload); err != nil {
+		return SchemaInfo{}, err
+	}
+
+	schema, err := c.cacheSchema(payload.ID, payload.Schema)
+	if err != nil {
+		return SchemaInfo{}, err
+	}
+
+	return SchemaInfo{
+		Schema:  schema,
+		ID:      payload.ID,
+		Version: payload.Version,
+	}, nil
+}
+
+// CreateSchema creates a schema in the registry, returning the schema id.
+func (c *Client) CreateSchema(ctx context.Context, subject, schema string) (int, base.Schema, error) {
+	var payload idPayload
+	p := "subjects/" + url.PathEscape(subject) + "/versions"
+	if err := c.request(ctx, http.MethodPost, p, schemaPayload{Schema: schema}, &payload); err != nil {
+		return 0, nil, err
+	}
+
+	sch, err := c.cacheSchema(payload.ID, schema)
+	if err != nil {
+		return 0, nil, err
+	}
+	return payload.ID, sch, nil
+}
+
+// IsRegistered determines if the schema is registered, returning the schema id.
+func (c *Client) IsRegistered(ctx context.Context, subject, schema string) (int, base.Schema, error) {
+	var payload idPayload
+	p := "subjects/" + url.PathEscape(subject)
+	if err := c.request(ctx, http.MethodPost, p, schemaPayload{Schema: schema}, &payload); err != nil {
+		return 0, nil, err
+	}
+
+	sch, err := c.cacheSchema(payload.ID, schema)
+	if err != nil {
+		return 0, nil, err
+	}
+	return payload.ID, sch, nil
+}
+
+// IsCompatible determines if the schema is compatible with the given subject version.
+func (c *Client) IsCompatible(ctx context.Context, subject string, version int, schema string) (bool, error) {
+	var payload compatPayload
+	p := "compatibility/subjects/" + url.PathEscape(subject) + "/versions/" + strconv.Itoa(version)
+	if err := c.request(ctx, http.MethodPost, p, schemaPayload{Schema: schema}, &payload); err != nil {
+		return false, err
+	}
+
+	return payload.IsCompatible, nil
+}
+
+func (c *Client) cacheSchema(id int, schema string) (base.Schema, error) {
+	if sch, ok := c.cache.Load(id); ok {
+		return sch.(base.Schema), nil
+	}
+
+	sch, err := parseSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.Store(id, sch)
+	return sch, nil
+}
+
+// parseSchema parses a registry schema. Each schema gets its own cache, as
+// different versions of a subject define the same names.
+func parseSchema(schema string) (base.Schema, error) {
+	return base.ParseWithCache(schema, "", &base.SchemaCache{})
+}
+
+func (c *Client) request(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := jsoniter.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	u, err := c.base.Parse(path)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if len(c.creds.username) > 0 || len(c.creds.password) > 0 {
+		req.SetBasicAuth(c.creds.username, c.creds.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("registry: could not perform request: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		err := Error{StatusCode: resp.StatusCode}
+		_ = jsoniter.NewDecoder(resp.Body).Decode(&err)
+		return err
+	}
+
+	if out != nil {
+		return jsoniter.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// Error is returned by the registry when there is an error.
+type Error struct {
+	StatusCode int `json:"-"`
+
+	Code    int    `json:"error_code"`
+	Message string `json:"message"`
+}
+
+// Error returns the error message.
+func (e Error) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return "registry error: " + strconv.Itoa(e.StatusCode)
+}
+
+// IsNotFound determines if the error is a not found error of the registry.
+func IsNotFound(err error) bool {
+	var regErr Error
+	if errors.As(err, &regErr) {
+		return regErr.StatusCode == http.StatusNotFound
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package registry_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aacfactory/avro/registry"
+)
+
+// fakeRegistry is an in memory stand-in of the schema registry API.
+type fakeRegistry struct {
+	mu       sync.Mutex
+	schemas  []string
+	subjects map[string][]int
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var in struct {
+		Schema string `json:"schema"`
+	}
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&in)
+	}
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	write := func(v any) {
+		w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		write(map[string]any{"error_code": 40401, "message": "Subject not found"})
+	}
+	idOf := func(schema string) int {
+		for i, s := range f.schemas {
+			if s == schema {
+				return i + 1
+			}
+		}
+		f.schemas = append(f.schemas, schema)
+		return len(f.schemas)
+	}
+
+	switch {
+	case len(path) == 3 && path[0] == "schemas":
+		id, _ := strconv.Atoi(path[2])
+		if id < 1 || id > len(f.schemas) {
+			notFound()
+			return
+		}
+		write(map[string]any{"schema": f.schemas[id-1]})
+	case len(path) == 1 && path[0] == "subjects":
+		subjects := make([]string, 0, len(f.subjects))
+		for subject := range f.subjects {
+			subjects = append(subjects, subject)
+		}
+		write(subjects)
+	case len(path) == 2 && path[0] == "subjects":
+		for _, id := range f.subjects[path[1]] {
+			if f.schemas[id-1] == in.Schema {
+				write(map[string]any{"id": id})
+				return
+			}
+		}
+		notFound()
+	case len(path) == 3 && path[0] == "subjects" && r.Method == http.MethodPost:
+		id := idOf(in.Schema)
+		f.subjects[path[1]] = append(f.subjects[path[1]], id)
+		write(map[string]any{"id": id})
+	case len(path) == 3 && path[0] == "subjects":
+		versions := make([]int, len(f.subjects[path[1]]))
+		for i := range versions {
+			versions[i] = i + 1
+		}
+		write(versions)
+	case len(path) == 4 && path[0] == "subjects":
+		ids := f.subjects[path[1]]
+		version := len(ids)
+		if path[3] != "latest" {
+			version, _ = strconv.Atoi(path[3])
+		}
+		if version < 1 || version > len(ids) {
+			notFound()
+			return
+		}
+		id := ids[version-1]
+		write(map[string]any{"schema": f.schemas[id-1], "id": id, "version": version})
+	case len(path) == 5 && path[0] == "compatibility":
+		write(map[string]any{"is_compatible": true})
+	default:
+		notFound()
+	}
+}
+
+type User struct {
+	Name  string `avro:"name"`
+	Age   int    `avro:"age"`
+	Email string `avro:"email,default=\"\""`
+}
+
+func TestClient(t *testing.T) {
+	fake := &fakeRegistry{subjects: map[string][]int{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client, err := registry.NewClient(srv.URL)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ctx := context.Background()
+
+	_, _, err = client.IsRegistered(ctx, "users", `"string"`)
+	if !registry.IsNotFound(err) {
+		t.Error("expected not found", err)
+		return
+	}
+	id, schema, err := client.CreateSchema(ctx, "users", `{"name":"User","type":"record","fields":[{"name":"name","type":"string"}]}`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(id, schema)
+	info, err := client.GetLatestSchemaInfo(ctx, "users")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(info.ID, info.Version, info.Schema)
+	versions, err := client.GetVersions(ctx, "users")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(versions)
+	ok, err := client.IsCompatible(ctx, "users", 1, `{"type":"record","name":"User","fields":[]}`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(ok)
+
+	// Old data written with the first version of the schema
+	old, err := client.GetSchema(ctx, id)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p, err := registry.NewSerializer(client, "users").SerializeWithSchema(ctx, old, map[string]any{"name": "foo"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	serializer := registry.NewSerializer(client, "users", registry.WithAutoRegister())
+	q, err := serializer.Serialize(ctx, User{Name: "bar", Age: 18, Email: "bar@foo.com"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	qid, err := registry.WireHeader(q)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(qid)
+	if registered := fake.schemas[qid-1]; !strings.Contains(registered, `"default":""`) {
+		t.Error("defaults are not registered", registered)
+		return
+	}
+
+	deserializer := registry.NewDeserializer(client)
+	v := User{}
+	if err = deserializer.Deserialize(ctx, q, &v); err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(v)
+
+	m := map[string]any{}
+	if err = deserializer.DeserializeWithSchema(ctx, old, p, &m); err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(m)
+}
@@ -0,0 +1,186 @@
+package registry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/aacfactory/avro/internal/base"
+)
+
+// magicByte is the first byte of the Confluent wire format.
+const magicByte = 0
+
+// headerSize is the size of the magic byte and the schema id.
+const headerSize = 5
+
+// WireHeader returns the schema id of the Confluent wire format data,
+// or an error if data is not in the wire format.
+func WireHeader(data []byte) (int, error) {
+	if len(data) < headerSize || data[0] != magicByte {
+		return 0, errors.New("registry: invalid wire format header")
+	}
+	return int(binary.BigEndian.Uint32(data[1:headerSize])), nil
+}
+
+// AppendWireHeader appends the Confluent wire format header of the schema id to p.
+func AppendWireHeader(p []byte, id int) []byte {
+	p = append(p, magicByte)
+	return binary.BigEndian.AppendUint32(p, uint32(id))
+}
+
+type serializerConfig struct {
+	autoRegister bool
+	api          base.API
+}
+
+// SerializerFunc is a function used to customize the Serializer.
+type SerializerFunc func(*serializerConfig)
+
+// WithAutoRegister registers schemas missing from the subject instead of returning an error.
+func WithAutoRegister() SerializerFunc {
+	return func(cfg *serializerConfig) {
+		cfg.autoRegister = true
+	}
+}
+
+// WithSerializerConfig sets the value encoder config on the serializer.
+func WithSerializerConfig(api base.API) SerializerFunc {
+	return func(cfg *serializerConfig) {
+		cfg.api = api
+	}
+}
+
+// Serializer encodes values in the Confluent wire format for a subject.
+type Serializer struct {
+	registry     Registry
+	subject      string
+	autoRegister bool
+	api          base.API
+
+	ids sync.Map // map[string]int
+}
+
+// NewSerializer returns a new serializer encoding values of the subject.
+func NewSerializer(registry Registry, subject string, opts ...SerializerFunc) *Serializer {
+	cfg := serializerConfig{api: base.DefaultConfig}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &Serializer{
+		registry:     registry,
+		subject:      subject,
+		autoRegister: cfg.autoRegister,
+		api:          cfg.api,
+	}
+}
+
+// Serialize returns the Confluent wire format of v, using the schema derived from v with the config of the serializer.
+func (s *Serializer) Serialize(ctx context.Context, v any) ([]byte, error) {
+	schema, err := s.api.ParseValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return s.SerializeWithSchema(ctx, schema, v)
+}
+
+// SerializeWithSchema returns the Confluent wire format of v using schema.
+func (s *Serializer) SerializeWithSchema(ctx context.Context, schema base.Schema, v any) ([]byte, error) {
+	id, err := s.schemaID(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.api.Marshal(schema, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(AppendWireHeader(make([]byte, 0, headerSize+len(p)), id), p...), nil
+}
+
+// schemaID returns the id of the full JSON of the schema, as the canonical form drops defaults, aliases and docs
+// that the registry checks the compatibility of.
+func (s *Serializer) schemaID(ctx context.Context, schema base.Schema) (int, error) {
+	p, err := json.Marshal(schema)
+	if err != nil {
+		return 0, err
+	}
+	full := string(p)
+	if id, ok := s.ids.Load(full); ok {
+		return id.(int), nil
+	}
+
+	id, _, err := s.registry.IsRegistered(ctx, s.subject, full)
+	if err != nil && s.autoRegister && IsNotFound(err) {
+		id, _, err = s.registry.CreateSchema(ctx, s.subject, full)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	s.ids.Store(full, id)
+	return id, nil
+}
+
+type deserializerConfig struct {
+	api base.API
+}
+
+// DeserializerFunc is a function used to customize the Deserializer.
+type DeserializerFunc func(*deserializerConfig)
+
+// WithDeserializerConfig sets the value decoder config on the deserializer.
+func WithDeserializerConfig(api base.API) DeserializerFunc {
+	return func(cfg *deserializerConfig) {
+		cfg.api = api
+	}
+}
+
+// Deserializer decodes values in the Confluent wire format.
+type Deserializer struct {
+	registry Registry
+	api      base.API
+}
+
+// NewDeserializer returns a new deserializer that looks up writer schemas in the registry.
+func NewDeserializer(registry Registry, opts ...DeserializerFunc) *Deserializer {
+	cfg := deserializerConfig{api: base.DefaultConfig}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &Deserializer{
+		registry: registry,
+		api:      cfg.api,
+	}
+}
+
+// Deserialize parses the Confluent wire format data and stores the result in the value pointed to by v.
+// The writer schema is resolved against the schema derived from v.
+func (d *Deserializer) Deserialize(ctx context.Context, data []byte, v any) error {
+	schema, err := d.api.ParseValue(v)
+	if err != nil {
+		return err
+	}
+	return d.DeserializeWithSchema(ctx, schema, data, v)
+}
+
+// DeserializeWithSchema parses the Confluent wire format data and stores the result in the value pointed to by v.
+// The writer schema is resolved against the reader schema.
+func (d *Deserializer) DeserializeWithSchema(ctx context.Context, schema base.Schema, data []byte, v any) error {
+	id, err := WireHeader(data)
+	if err != nil {
+		return err
+	}
+
+	writer, err := d.registry.GetSchema(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return d.api.UnmarshalWithWriterSchema(schema, writer, data[headerSize:], v)
+}
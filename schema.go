@@ -0,0 +1,271 @@
+package avro
+
+import (
+	"github.com/aacfactory/avro/internal/base"
+)
+
+// Type is a schema type.
+type Type = base.Type
+
+// Schema type constants.
+const (
+	Record  = base.Record
+	Error   = base.Error
+	Ref     = base.Ref
+	Enum    = base.Enum
+	Array   = base.Array
+	Map     = base.Map
+	Union   = base.Union
+	Fixed   = base.Fixed
+	String  = base.String
+	Bytes   = base.Bytes
+	Int     = base.Int
+	Long    = base.Long
+	Float   = base.Float
+	Double  = base.Double
+	Boolean = base.Boolean
+	Null    = base.Null
+
+	// Deprecated: Raw is not an Avro type, Marshaler types are bytes.
+	Raw = base.Raw
+)
+
+// Order is a field order.
+type Order = base.Order
+
+// Field orders.
+const (
+	Asc    = base.Asc
+	Desc   = base.Desc
+	Ignore = base.Ignore
+)
+
+// LogicalType is a schema logical type.
+type LogicalType = base.LogicalType
+
+// Schema logical type constants.
+const (
+	Decimal         = base.Decimal
+	UUID            = base.UUID
+	Date            = base.Date
+	TimeMillis      = base.TimeMillis
+	TimeMicros      = base.TimeMicros
+	TimestampMillis = base.TimestampMillis
+	TimestampMicros = base.TimestampMicros
+	Duration        = base.Duration
+
+	TimestampNanos       = base.TimestampNanos
+	LocalTimestampMillis = base.LocalTimestampMillis
+	LocalTimestampMicros = base.LocalTimestampMicros
+	LocalTimestampNanos  = base.LocalTimestampNanos
+)
+
+// FingerprintType is a fingerprinting algorithm.
+type FingerprintType = base.FingerprintType
+
+// Fingerprint type constants.
+const (
+	CRC64Avro = base.CRC64Avro
+	MD5       = base.MD5
+	SHA256    = base.SHA256
+)
+
+// LogicalDuration represents the `duration` logical type.
+type LogicalDuration = base.LogicalDuration
+
+type (
+	// Schema represents an Avro schema.
+	Schema = base.Schema
+	// Schemas is a slice of Schemas.
+	Schemas = base.Schemas
+	// LogicalSchema represents an Avro schema with a logical type.
+	LogicalSchema = base.LogicalSchema
+	// PropertySchema represents a schema with properties.
+	PropertySchema = base.PropertySchema
+	// NamedSchema represents a schema with a name.
+	NamedSchema = base.NamedSchema
+	// LogicalTypeSchema represents a schema that can contain a logical type.
+	LogicalTypeSchema = base.LogicalTypeSchema
+	// PrimitiveSchema is an Avro primitive type schema.
+	PrimitiveSchema = base.PrimitiveSchema
+	// RecordSchema is an Avro record type schema.
+	RecordSchema = base.RecordSchema
+	// Field is an Avro record type field.
+	Field = base.Field
+	// EnumSchema is an Avro enum type schema.
+	EnumSchema = base.EnumSchema
+	// ArraySchema is an Avro array type schema.
+	ArraySchema = base.ArraySchema
+	// MapSchema is an Avro map type schema.
+	MapSchema = base.MapSchema
+	// UnionSchema is an Avro union type schema.
+	UnionSchema = base.UnionSchema
+	// FixedSchema is an Avro fixed type schema.
+	FixedSchema = base.FixedSchema
+	// NullSchema is an Avro null type schema.
+	NullSchema = base.NullSchema
+	// RefSchema is a reference to a named Avro schema.
+	RefSchema = base.RefSchema
+	// PrimitiveLogicalSchema is a logical type with no properties.
+	PrimitiveLogicalSchema = base.PrimitiveLogicalSchema
+	// DecimalLogicalSchema is a decimal logical type.
+	DecimalLogicalSchema = base.DecimalLogicalSchema
+	// SchemaCache is a cache of schemas.
+	SchemaCache = base.SchemaCache
+	// SchemaCompatibility determines the compatibility of schemas.
+	SchemaCompatibility = base.SchemaCompatibility
+	// CompatibilityMode is a registry style mode of schema evolution.
+	CompatibilityMode = base.CompatibilityMode
+	// CompatibilityReport lists the incompatibilities of a schema with its versions.
+	CompatibilityReport = base.CompatibilityReport
+	// Incompatibility is an incompatible change of the schema from a version.
+	Incompatibility = base.Incompatibility
+	// SchemaOption is a function that sets a schema option.
+	SchemaOption = base.SchemaOption
+)
+
+// Compatibility modes.
+const (
+	// CompatibilityNone checks nothing.
+	CompatibilityNone = base.CompatibilityNone
+	// CompatibilityBackward checks that the schema reads data of the latest version.
+	CompatibilityBackward = base.CompatibilityBackward
+	// CompatibilityBackwardTransitive checks that the schema reads data of all versions.
+	CompatibilityBackwardTransitive = base.CompatibilityBackwardTransitive
+	// CompatibilityForward checks that the latest version reads data of the schema.
+	CompatibilityForward = base.CompatibilityForward
+	// CompatibilityForwardTransitive checks that all versions read data of the schema.
+	CompatibilityForwardTransitive = base.CompatibilityForwardTransitive
+	// CompatibilityFull checks both backward and forward against the latest version.
+	CompatibilityFull = base.CompatibilityFull
+	// CompatibilityFullTransitive checks both backward and forward against all versions.
+	CompatibilityFullTransitive = base.CompatibilityFullTransitive
+)
+
+// NoDefault is used when no default exists for a field.
+var NoDefault = base.NoDefault
+
+// DefaultSchemaCache is the default cache for schemas.
+var DefaultSchemaCache = base.DefaultSchemaCache
+
+// WithAliases sets the aliases on a schema.
+func WithAliases(aliases []string) SchemaOption {
+	return base.WithAliases(aliases)
+}
+
+// WithDoc sets the doc on a schema.
+func WithDoc(doc string) SchemaOption {
+	return base.WithDoc(doc)
+}
+
+// WithDefault sets the default on a schema.
+func WithDefault(def any) SchemaOption {
+	return base.WithDefault(def)
+}
+
+// WithOrder sets the order on a schema.
+func WithOrder(order Order) SchemaOption {
+	return base.WithOrder(order)
+}
+
+// WithProps sets the properties on a schema.
+func WithProps(props map[string]any) SchemaOption {
+	return base.WithProps(props)
+}
+
+// NewPrimitiveSchema creates a new PrimitiveSchema.
+func NewPrimitiveSchema(t Type, l LogicalSchema, opts ...SchemaOption) *PrimitiveSchema {
+	return base.NewPrimitiveSchema(t, l, opts...)
+}
+
+// NewRecordSchema creates a new record schema instance.
+func NewRecordSchema(name, namespace string, fields []*Field, opts ...SchemaOption) (*RecordSchema, error) {
+	return base.NewRecordSchema(name, namespace, fields, opts...)
+}
+
+// NewErrorRecordSchema creates a new error record schema instance.
+func NewErrorRecordSchema(name, namespace string, fields []*Field, opts ...SchemaOption) (*RecordSchema, error) {
+	return base.NewErrorRecordSchema(name, namespace, fields, opts...)
+}
+
+// NewField creates a new field instance.
+func NewField(name string, typ Schema, opts ...SchemaOption) (*Field, error) {
+	return base.NewField(name, typ, opts...)
+}
+
+// NewEnumSchema creates a new enum schema instance.
+func NewEnumSchema(name, namespace string, symbols []string, opts ...SchemaOption) (*EnumSchema, error) {
+	return base.NewEnumSchema(name, namespace, symbols, opts...)
+}
+
+// NewArraySchema creates an array schema instance.
+func NewArraySchema(items Schema, opts ...SchemaOption) *ArraySchema {
+	return base.NewArraySchema(items, opts...)
+}
+
+// NewMapSchema creates a map schema instance.
+func NewMapSchema(values Schema, opts ...SchemaOption) *MapSchema {
+	return base.NewMapSchema(values, opts...)
+}
+
+// NewUnionSchema creates a union schema instance.
+func NewUnionSchema(types []Schema) (*UnionSchema, error) {
+	return base.NewUnionSchema(types)
+}
+
+// NewFixedSchema creates a new fixed schema instance.
+func NewFixedSchema(name, namespace string, size int, logical LogicalSchema, opts ...SchemaOption) (*FixedSchema, error) {
+	return base.NewFixedSchema(name, namespace, size, logical, opts...)
+}
+
+// NewRefSchema creates a ref schema instance.
+func NewRefSchema(schema NamedSchema) *RefSchema {
+	return base.NewRefSchema(schema)
+}
+
+// NewPrimitiveLogicalSchema creates a new primitive logical schema instance.
+func NewPrimitiveLogicalSchema(typ LogicalType) *PrimitiveLogicalSchema {
+	return base.NewPrimitiveLogicalSchema(typ)
+}
+
+// NewDecimalLogicalSchema creates a new decimal logical schema instance.
+func NewDecimalLogicalSchema(prec, scale int) *DecimalLogicalSchema {
+	return base.NewDecimalLogicalSchema(prec, scale)
+}
+
+// NewSchemaCompatibility creates a new schema compatibility instance.
+func NewSchemaCompatibility() *SchemaCompatibility {
+	return base.NewSchemaCompatibility()
+}
+
+// Parse parses a schema string.
+func Parse(schema string) (Schema, error) {
+	return base.Parse(schema)
+}
+
+// ParseWithCache parses a schema string using the given namespace and schema cache.
+func ParseWithCache(schema, namespace string, cache *SchemaCache) (Schema, error) {
+	return base.ParseWithCache(schema, namespace, cache)
+}
+
+// MustParse parses a schema string, panicing if there is an error.
+func MustParse(schema string) Schema {
+	return base.MustParse(schema)
+}
+
+// ParseFiles parses the schemas in the files, in the order they appear, returning the last schema.
+//
+// This is useful when your schemas rely on other schemas.
+func ParseFiles(paths ...string) (Schema, error) {
+	return base.ParseFiles(paths...)
+}
+
+// ParseBytes parses a schema byte slice.
+func ParseBytes(schema []byte) (Schema, error) {
+	return base.ParseBytes(schema)
+}
+
+// ParseBytesWithCache parses a schema byte slice using the given namespace and schema cache.
+func ParseBytesWithCache(schema []byte, namespace string, cache *SchemaCache) (Schema, error) {
+	return base.ParseBytesWithCache(schema, namespace, cache)
+}
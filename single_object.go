@@ -0,0 +1,52 @@
+package avro
+
+import (
+	"github.com/aacfactory/avro/internal/base"
+)
+
+type (
+	// SchemaStore looks up writer schemas by their CRC-64-AVRO fingerprint.
+	SchemaStore = base.SchemaStore
+	// MemorySchemaStore is an in memory SchemaStore.
+	MemorySchemaStore = base.MemorySchemaStore
+)
+
+// NewSchemaStore creates a new in memory schema store holding schemas.
+func NewSchemaStore(schemas ...Schema) (*MemorySchemaStore, error) {
+	return base.NewSchemaStore(schemas...)
+}
+
+// SingleObjectFingerprint returns the CRC-64-AVRO fingerprint of the schema.
+func SingleObjectFingerprint(schema Schema) (uint64, error) {
+	return base.SingleObjectFingerprint(schema)
+}
+
+// SingleObjectHeader returns the fingerprint of the writer schema from the
+// single object encoded data, or an error if data is not single object encoded.
+func SingleObjectHeader(data []byte) (uint64, error) {
+	return base.SingleObjectHeader(data)
+}
+
+// MarshalSingleObject returns the Avro single object encoding of v, using the schema derived from v.
+func MarshalSingleObject(v any) (p []byte, err error) {
+	schema, schemaErr := base.ParseValue(v)
+	if schemaErr != nil {
+		err = schemaErr
+		return
+	}
+	p, err = base.MarshalSingleObject(schema, v)
+	return
+}
+
+// UnmarshalSingleObject parses the Avro single object encoded data and stores the result in the value pointed to by v.
+// When the data was not written with the schema derived from v, the writer schema is looked up in the store and
+// resolved against it. The store may be nil.
+func UnmarshalSingleObject(store SchemaStore, p []byte, v any) (err error) {
+	schema, schemaErr := base.ParseValue(v)
+	if schemaErr != nil {
+		err = schemaErr
+		return
+	}
+	err = base.UnmarshalSingleObject(store, schema, p, v)
+	return
+}